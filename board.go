@@ -0,0 +1,256 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color of a point on the board.
+type Color int
+
+const (
+	Empty Color = iota
+	Black
+	White
+)
+
+// Returns the color of the opponent. Empty has no opponent and returns Empty.
+func (color Color) Opponent() Color {
+	switch color {
+	case Black:
+		return White
+	case White:
+		return Black
+	}
+
+	return Empty
+}
+
+// Board is a Go board position.
+type Board struct {
+	Width  int
+	Height int
+	points []Color
+}
+
+// Creates a new empty board of the given size.
+func NewBoard(width, height int) *Board {
+	return &Board{width, height, make([]Color, width*height)}
+}
+
+// Returns the color of the given point. Points outside the board are Empty.
+func (board *Board) At(point Point) Color {
+	if !point.OnBoard(board.Width, board.Height) {
+		return Empty
+	}
+
+	return board.points[point.Y*board.Width+point.X]
+}
+
+// Sets the color of the given point without capturing any stones. Panics if the point is not on the board.
+func (board *Board) Set(point Point, color Color) {
+	if !point.OnBoard(board.Width, board.Height) {
+		panic(fmt.Sprintf("point %v is not on the board", point))
+	}
+
+	board.points[point.Y*board.Width+point.X] = color
+}
+
+// Plays a stone of the given color to the point. Opponent stones without liberties are captured. If the stone's own
+// group has no liberties after that, the group is removed (suicide). Returns the captured points.
+func (board *Board) Play(point Point, color Color) ([]Point, error) {
+	if !point.OnBoard(board.Width, board.Height) {
+		return nil, errors.New(fmt.Sprintf("Move %s is not on the board", point))
+	}
+
+	board.Set(point, color)
+
+	captured := []Point{}
+	for _, neighbor := range board.neighbors(point) {
+		if board.At(neighbor) == color.Opponent() {
+			captured = append(captured, board.captureGroup(neighbor)...)
+		}
+	}
+
+	if len(captured) == 0 {
+		captured = board.captureGroup(point)
+	}
+
+	return captured, nil
+}
+
+// Returns a copy of the board.
+func (board *Board) Copy() *Board {
+	return &Board{board.Width, board.Height, append([]Color{}, board.points...)}
+}
+
+// Returns the points of the group at the given point.
+func (board *Board) Group(point Point) []Point {
+	color := board.At(point)
+	if color == Empty {
+		return []Point{}
+	}
+
+	group := []Point{point}
+	visited := map[Point]bool{point: true}
+
+	for i := 0; i < len(group); i++ {
+		for _, neighbor := range board.neighbors(group[i]) {
+			if !visited[neighbor] && board.At(neighbor) == color {
+				visited[neighbor] = true
+				group = append(group, neighbor)
+			}
+		}
+	}
+
+	return group
+}
+
+// Returns the number of liberties of the group at the given point.
+func (board *Board) Liberties(point Point) int {
+	liberties := map[Point]bool{}
+
+	for _, p := range board.Group(point) {
+		for _, neighbor := range board.neighbors(p) {
+			if board.At(neighbor) == Empty {
+				liberties[neighbor] = true
+			}
+		}
+	}
+
+	return len(liberties)
+}
+
+// Removes the group at the given point if it does not have any liberties. Returns the removed points.
+func (board *Board) captureGroup(point Point) []Point {
+	if board.Liberties(point) > 0 {
+		return []Point{}
+	}
+
+	group := board.Group(point)
+	for _, p := range group {
+		board.Set(p, Empty)
+	}
+
+	return group
+}
+
+func (board *Board) neighbors(point Point) []Point {
+	neighbors := []Point{}
+
+	for _, p := range []Point{{point.X, point.Y - 1}, {point.X - 1, point.Y}, {point.X + 1, point.Y}, {point.X, point.Y + 1}} {
+		if p.OnBoard(board.Width, board.Height) {
+			neighbors = append(neighbors, p)
+		}
+	}
+
+	return neighbors
+}
+
+// Returns the board size set by the SZ property of this Node. Square boards have a single value ("19") and
+// rectangular boards a composed value of the width and the height ("19:13"). If there is no SZ property, the default
+// 19x19 is returned.
+func (node *Node) BoardSize() (int, int, error) {
+	property := node.Property("SZ")
+	if property == nil || len(property.Values) == 0 {
+		return 19, 19, nil
+	}
+
+	first, second, composed := Compose(property.Values[0]).Split()
+	if !composed {
+		second = first
+	}
+
+	width, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil || width < 1 || width > 52 {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid board size %q", property.Values[0]))
+	}
+
+	height, err := strconv.Atoi(strings.TrimSpace(second))
+	if err != nil || height < 1 || height > 52 {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid board size %q", property.Values[0]))
+	}
+
+	return width, height, nil
+}
+
+// Returns the move (B or W) of this Node on a board of the given size. Color is Empty if the Node does not contain
+// a move. Pass is an empty value or "tt" on boards up to 19x19.
+func (node *Node) Move(width, height int) (color Color, point Point, pass bool, err error) {
+	for _, property := range node.Properties {
+		switch property.Ident {
+		case "B":
+			color = Black
+		case "W":
+			color = White
+		default:
+			continue
+		}
+
+		if len(property.Values) == 0 || property.Values[0] == "" || (property.Values[0] == "tt" && width <= 19 && height <= 19) {
+			return color, Point{}, true, nil
+		}
+
+		point, err = ParsePoint(property.Values[0])
+		if err == nil && !point.OnBoard(width, height) {
+			err = errors.New(fmt.Sprintf("Move %s is not on the board", point))
+		}
+
+		return color, point, false, err
+	}
+
+	return Empty, Point{}, false, nil
+}
+
+// Returns the board position after the last Node of the given path (see GameTree.PathTo). Board size is read from the
+// first Node. Setup properties (AB, AW, AE) are applied before the moves of each Node.
+func Position(path []*Node) (*Board, error) {
+	if len(path) == 0 {
+		return nil, errors.New("Empty path")
+	}
+
+	width, height, err := path[0].BoardSize()
+	if err != nil {
+		return nil, err
+	}
+
+	board := NewBoard(width, height)
+
+	for _, node := range path {
+		for _, setup := range []struct {
+			ident string
+			color Color
+		}{{"AE", Empty}, {"AB", Black}, {"AW", White}} {
+			property := node.Property(setup.ident)
+			if property == nil {
+				continue
+			}
+
+			points, err := ParsePointList(property.Values...)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, point := range points {
+				if !point.OnBoard(width, height) {
+					return nil, errors.New(fmt.Sprintf("%s %s is not on the board", setup.ident, point))
+				}
+
+				board.Set(point, setup.color)
+			}
+		}
+
+		color, point, pass, err := node.Move(width, height)
+		if err != nil {
+			return nil, err
+		}
+
+		if color != Empty && !pass {
+			board.Play(point, color)
+		}
+	}
+
+	return board, nil
+}
@@ -0,0 +1,128 @@
+package sgf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBoardPlay(t *testing.T) {
+	var tests = []struct {
+		black    string
+		white    string
+		move     string
+		color    Color
+		captured int
+	}{
+		{"", "", "dd", Black, 0},
+		{"ab", "aa", "ba", Black, 1},                // corner capture
+		{"ab,bb", "aa,ba", "da", Black, 0},          // group still has liberties
+		{"ab,bb,da", "aa,ba", "ca", Black, 2},       // group capture
+		{"ba,ab", "", "aa", White, 1},               // suicide removes the own stone
+		{"ba,ab,cb,bc", "ca,ac,bb", "aa", White, 2}, // capture before suicide
+	}
+
+	for _, test := range tests {
+		board := NewBoard(19, 19)
+		setupPoints(t, board, test.black, Black)
+		setupPoints(t, board, test.white, White)
+
+		point, _ := ParsePoint(test.move)
+		captured, err := board.Play(point, test.color)
+		if err != nil {
+			t.Errorf("Play(%s) returned error: %s", test.move, err)
+			continue
+		}
+
+		if len(captured) != test.captured {
+			t.Errorf("Play(%s) captured %d stones, wanted %d.", test.move, len(captured), test.captured)
+		}
+	}
+
+	if _, err := NewBoard(9, 9).Play(Point{9, 0}, Black); err == nil {
+		t.Errorf("Play did not return error.")
+	}
+}
+
+func setupPoints(t *testing.T, board *Board, values string, color Color) {
+	if values == "" {
+		return
+	}
+
+	points, err := ParsePointList(strings.Split(values, ",")...)
+	if err != nil {
+		t.Fatalf("ParsePointList(%s) returned error: %s", values, err)
+	}
+
+	for _, point := range points {
+		board.Set(point, color)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	var tests = []struct {
+		data   string
+		black  string
+		white  string
+		width  int
+		height int
+	}{
+		{"(;SZ[9];B[aa];W[ba];B[bb];W[ab])", "bb", "ba,ab", 9, 9},
+		{"(;SZ[9:5]AB[aa:cc]AE[bb];W[bb])", "aa,ba,ca,ab,cb,ac,bc,cc", "", 9, 5},
+		{"(;AW[aa];B[ba];W[];B[ab])", "ba,ab", "", 19, 19},
+		{"(;SZ[19];B[tt];W[aa])", "", "aa", 19, 19},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.data, err)
+		}
+
+		board, err := Position(collection.GameTrees[0].MainLine())
+		if err != nil {
+			t.Errorf("Position(%s) returned error: %s", test.data, err)
+			continue
+		}
+
+		if board.Width != test.width || board.Height != test.height {
+			t.Errorf("Position(%s) size mismatch. Got: %dx%d.", test.data, board.Width, board.Height)
+		}
+
+		wanted := NewBoard(test.width, test.height)
+		setupPoints(t, wanted, test.black, Black)
+		setupPoints(t, wanted, test.white, White)
+
+		for y := 0; y < board.Height; y++ {
+			for x := 0; x < board.Width; x++ {
+				if board.At(Point{x, y}) != wanted.At(Point{x, y}) {
+					t.Errorf("Position(%s) mismatch at %s. wanted: %d, got: %d.", test.data, Point{x, y}, wanted.At(Point{x, y}), board.At(Point{x, y}))
+				}
+			}
+		}
+	}
+}
+
+func TestPositionErrors(t *testing.T) {
+	var errTests = []string{
+		"(;SZ[foo])",
+		"(;SZ[0])",
+		"(;SZ[9];B[jj])",
+		"(;SZ[9]AB[aa:jj])",
+		"(;SZ[9];W[a])",
+	}
+
+	for _, test := range errTests {
+		collection, err := ParseSgf(test)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test, err)
+		}
+
+		if _, err := Position(collection.GameTrees[0].MainLine()); err == nil {
+			t.Errorf("Position(%s) did not return error.", test)
+		}
+	}
+
+	if _, err := Position([]*Node{}); err == nil {
+		t.Errorf("Position did not return error for empty path.")
+	}
+}
@@ -0,0 +1,119 @@
+package sgf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+)
+
+// Format used for the canonical form, see Canonicalize.
+var CanonicalSgfFormat = SgfFormat{PropertyOrder: CanonicalPropertyOrder, SortProperties: true}
+
+// Returns a normalized copy of the collection. Two semantically identical collections have identical canonical forms:
+//   - GameTrees with a single child GameTree are merged with the child.
+//   - Properties with the same ident in a Node are merged.
+//   - Text and SimpleText values are decoded (see Property.DecodedValues).
+//   - Point lists are expanded and compressed again in a canonical way.
+//   - Real values are written in their shortest form.
+//   - Values of list properties are sorted and duplicates are removed.
+//
+// Properties are ordered when the canonical form is written with CanonicalSgfFormat.
+func Canonicalize(collection *Collection) *Collection {
+	canonical := &Collection{}
+
+	for _, gameTree := range collection.GameTrees {
+		canonical.AddGameTree(canonicalGameTree(gameTree))
+	}
+
+	return canonical
+}
+
+// Returns a stable digest of the collection. Collections having the same canonical form have the same hash.
+func (collection *Collection) Hash() string {
+	sum := sha256.Sum256([]byte(Canonicalize(collection).Sgf(CanonicalSgfFormat)))
+	return hex.EncodeToString(sum[:])
+}
+
+func canonicalGameTree(gameTree *GameTree) *GameTree {
+	canonical := &GameTree{}
+
+	for {
+		for _, node := range gameTree.Nodes {
+			canonical.AddNode(canonicalNode(node))
+		}
+
+		// Merge single child GameTrees
+		if len(gameTree.GameTrees) != 1 {
+			break
+		}
+
+		gameTree = gameTree.GameTrees[0]
+	}
+
+	for _, childGameTree := range gameTree.GameTrees {
+		canonical.AddGameTree(canonicalGameTree(childGameTree))
+	}
+
+	return canonical
+}
+
+func canonicalNode(node *Node) *Node {
+	canonical := &Node{}
+	merged := map[string]*Property{}
+
+	for _, property := range node.Properties {
+		values := canonicalValues(property)
+
+		if p, ok := merged[property.Ident]; ok {
+			p.Values = append(p.Values, values...)
+			continue
+		}
+
+		merged[property.Ident] = canonical.NewProperty(property.Ident, values...)
+	}
+
+	for _, property := range canonical.Properties {
+		info := property.Info()
+
+		if info.List && info.Composed == ValueUnknown && (info.Value == ValuePoint || info.Value == ValueStone) {
+			// Expand and compress again, merged properties may overlap
+			if points, err := ParsePointList(property.Values...); err == nil && len(points) > 0 {
+				property.Values = CompressPoints(points)
+			}
+		}
+
+		if info.List {
+			property.Values = sortedUniqueValues(property.Values)
+		}
+	}
+
+	return canonical
+}
+
+func canonicalValues(property *Property) []string {
+	values := property.DecodedValues()
+
+	if property.Info().Value == ValueReal {
+		for i, value := range values {
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				values[i] = strconv.FormatFloat(f, 'f', -1, 64)
+			}
+		}
+	}
+
+	return values
+}
+
+func sortedUniqueValues(values []string) []string {
+	sort.Strings(values)
+
+	unique := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
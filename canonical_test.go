@@ -0,0 +1,63 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	var tests = []struct {
+		data   string
+		wanted string
+	}{
+		{"(;FF[4])", "(;FF[4])"},
+		{"(;SZ[19]FF[4]PB[Lee\nSedol]KM[6.50](;B[aa](;W[bb];B[cc])))", "(;FF[4]SZ[19]KM[6.5]PB[Lee Sedol];B[aa];W[bb];B[cc])"},
+		{"(;AB[bb][aa]AB[ab:bb][ba]CR[aa])", "(;AB[aa:bb]CR[aa])"},
+		{"(;C[a\tb\r\nc];B[aa](;W[bb])(;W[cc]))", "(;C[a b\nc];B[aa](;W[bb])(;W[cc]))"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Errorf("ParseSgf(%s) returned error.", test.data)
+			continue
+		}
+
+		original := collection.Sgf(NoNewLinesSgfFormat)
+
+		if sgf := Canonicalize(collection).Sgf(CanonicalSgfFormat); sgf != test.wanted {
+			t.Errorf("Canonicalize(%s) mismatch. wanted: %s, got: %s.", test.data, test.wanted, sgf)
+		}
+
+		// Original collection is not modified
+		if sgf := collection.Sgf(NoNewLinesSgfFormat); sgf != original {
+			t.Errorf("Canonicalize(%s) modified the original collection. Got: %s.", test.data, sgf)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	var equal = [][]string{
+		{"(;FF[4]GM[1];B[aa])", "(;GM[1]FF[4]\n(;B[aa]))"},
+		{"(;AB[aa][bb][ab][ba]KM[6.5])", "(;AB[aa:bb]KM[6.50])"},
+	}
+
+	for _, test := range equal {
+		c1, _ := ParseSgf(test[0])
+		c2, _ := ParseSgf(test[1])
+
+		if c1.Hash() != c2.Hash() {
+			t.Errorf("Hash() of %s and %s differ.", test[0], test[1])
+		}
+	}
+
+	c1, _ := ParseSgf("(;FF[4];B[aa])")
+	c2, _ := ParseSgf("(;FF[4];B[bb])")
+
+	if c1.Hash() == c2.Hash() {
+		t.Errorf("Hash() of different collections are equal.")
+	}
+
+	if len(c1.Hash()) != 64 {
+		t.Errorf("Hash() length mismatch. Got: %d.", len(c1.Hash()))
+	}
+}
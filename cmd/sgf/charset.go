@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Supported character sets by their normalized names, see normalizeCharset.
+const (
+	charsetUTF8   = "UTF8"
+	charsetLatin1 = "ISO88591"
+	charsetASCII  = "USASCII"
+)
+
+var charsetAliases = map[string]string{
+	"UTF8":     charsetUTF8,
+	"ISO88591": charsetLatin1,
+	"LATIN1":   charsetLatin1,
+	"L1":       charsetLatin1,
+	"USASCII":  charsetASCII,
+	"ASCII":    charsetASCII,
+}
+
+// Normalizes the name of a character set, e.g. "iso-8859-1" and "Latin1" both return "ISO88591".
+func normalizeCharset(charset string) (string, error) {
+	name := strings.ToUpper(strings.NewReplacer("-", "", "_", "", " ", "").Replace(charset))
+
+	if normalized, ok := charsetAliases[name]; ok {
+		return normalized, nil
+	}
+
+	return "", errors.New(fmt.Sprintf("unsupported charset %q", charset))
+}
+
+var charsetPattern = regexp.MustCompile(`CA\s*\[([^\]]*)\]`)
+
+// Returns the charset of SGF data given by the CA property or UTF-8 if there is no CA.
+func sgfCharset(data []byte) string {
+	if match := charsetPattern.FindSubmatch(data); match != nil {
+		return strings.TrimSpace(string(match[1]))
+	}
+
+	return "UTF-8"
+}
+
+// Converts SGF data to UTF-8 from the charset given by its CA property. Returns the text and the charset.
+func decodeSgf(data []byte) (string, string, error) {
+	charset := sgfCharset(data)
+
+	text, err := decodeCharset(data, charset)
+	if err != nil {
+		return "", "", err
+	}
+
+	return text, charset, nil
+}
+
+// Converts data in the character set to UTF-8. Byte order mark is removed.
+func decodeCharset(data []byte, charset string) (string, error) {
+	name, err := normalizeCharset(charset)
+	if err != nil {
+		return "", err
+	}
+
+	switch name {
+	case charsetLatin1:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+
+		return string(runes), nil
+	case charsetASCII:
+		for _, b := range data {
+			if b >= utf8.RuneSelf {
+				return "", errors.New(fmt.Sprintf("invalid %s character 0x%02x", charset, b))
+			}
+		}
+	}
+
+	if !utf8.Valid(data) {
+		return "", errors.New(fmt.Sprintf("invalid %s data", charset))
+	}
+
+	return string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), nil
+}
+
+// Converts UTF-8 text to the character set. Characters missing from the character set are errors.
+func encodeCharset(text string, charset string) ([]byte, error) {
+	name, err := normalizeCharset(charset)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := utf8.MaxRune
+	switch name {
+	case charsetLatin1:
+		limit = 0xff
+	case charsetASCII:
+		limit = 0x7f
+	}
+
+	if limit == utf8.MaxRune {
+		return []byte(text), nil
+	}
+
+	data := make([]byte, 0, len(text))
+	for _, r := range text {
+		if r > rune(limit) {
+			return nil, errors.New(fmt.Sprintf("character %q cannot be encoded in %s", r, charset))
+		}
+
+		data = append(data, byte(r))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,542 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/toikarin/sgf"
+	"github.com/toikarin/sgf/gib"
+	"github.com/toikarin/sgf/ngf"
+	"github.com/toikarin/sgf/raster"
+	"github.com/toikarin/sgf/svg"
+	"github.com/toikarin/sgf/ugf"
+	"github.com/toikarin/sgf/xmlsgf"
+)
+
+// Format that can be read.
+type inputFormat struct {
+	name       string
+	extensions []string
+	parse      func(data string) (*sgf.Collection, error)
+}
+
+var inputFormats = []inputFormat{
+	{"sgf", []string{".sgf"}, sgf.ParseSgf},
+	{"json", []string{".json"}, func(data string) (*sgf.Collection, error) { return sgf.ParseJSON([]byte(data)) }},
+	{"xml", []string{".xml"}, func(data string) (*sgf.Collection, error) { return xmlsgf.Parse([]byte(data)) }},
+	{"gib", []string{".gib"}, gib.Parse},
+	{"ngf", []string{".ngf"}, ngf.Parse},
+	{"ugf", []string{".ugf", ".ugi"}, ugf.Parse},
+}
+
+// Format that can be written.
+type outputFormat struct {
+	name      string
+	extension string
+	write     func(collection *sgf.Collection, options convertOptions) ([]byte, error)
+}
+
+var outputFormats = []outputFormat{
+	{"sgf", ".sgf", writeSgf},
+	{"json", ".json", writeJSON},
+	{"xml", ".xml", writeXML},
+	{"gib", ".gib", writeGib},
+	{"ugf", ".ugf", writeUgf},
+	{"txt", ".txt", writeDiagram},
+	{"svg", ".svg", writeSVG},
+	{"png", ".png", writePNG},
+	{"gif", ".gif", writeGIF},
+}
+
+// Options of the convert command.
+type convertOptions struct {
+	from         *inputFormat // nil = detect
+	to           *outputFormat
+	inputCharset string // "" = CA property of SGF input, UTF-8 otherwise
+	charset      string // charset of SGF output, "" = UTF-8 without changing CA
+	format       sgf.SgfFormat
+	typed        bool // typed JSON values
+	flat         bool // flat JSON schema
+	game         int  // GameTree of single game formats, counted from 1
+	move         int  // move of the images and diagrams, -1 = last
+	unicode      bool // Unicode diagrams
+	size         int  // point size of the images in pixels
+}
+
+func runConvert(e *env, args []string) int {
+	flags := newFlagSet(e, "convert", "[flags] [path ...]")
+	from := flags.String("from", "", "input format: sgf, json, xml, gib, ngf or ugf, detected from the extension or the content by default")
+	to := flags.String("to", "", "output format: sgf, json, xml, gib, ugf, txt, svg, png or gif, taken from the extension of -o by default")
+	output := flags.String("o", "", "output file or directory, standard output by default")
+	inputCharset := flags.String("input-charset", "", "charset of the input: UTF-8, ISO-8859-1 or US-ASCII, CA property of SGF input by default")
+	charset := flags.String("charset", "", "charset of SGF output: UTF-8, ISO-8859-1 or US-ASCII")
+	style := flags.String("style", "default", "SGF output format: default, cgoban or compact")
+	typed := flags.Bool("typed", false, "convert JSON values using the property registry")
+	flat := flags.Bool("flat", false, "write JSON as a flat node list")
+	game := flags.Int("game", 1, "game of GIB, UGF, diagram and image output")
+	move := flags.Int("move", -1, "move of the main line shown by diagrams and images, -1 = last")
+	unicode := flags.Bool("unicode", false, "draw Unicode text diagrams")
+	size := flags.Int("size", 24, "distance of the grid lines of images in pixels")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	options := convertOptions{
+		inputCharset: *inputCharset,
+		charset:      *charset,
+		typed:        *typed,
+		flat:         *flat,
+		game:         *game,
+		move:         *move,
+		unicode:      *unicode,
+		size:         *size,
+	}
+
+	var err error
+	if options.format, err = sgfStyle(*style); err == nil {
+		options.from, options.to, err = convertFormats(*from, *to, *output)
+	}
+
+	if err == nil && options.charset != "" {
+		_, err = normalizeCharset(options.charset)
+	}
+
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		return convertFile(e, inputFile{stdinName, stdinName}, *output, options)
+	}
+
+	files, err := findFiles(flags.Args(), inputExtensions(options.from)...)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	// Several inputs are written to a directory
+	if len(files) > 1 || (len(files) == 1 && isDir(flags.Arg(0))) {
+		if *output == "" {
+			fmt.Fprintf(e.stderr, "sgf: output directory (-o) is required for several inputs\n")
+			return 2
+		}
+
+		if err := os.MkdirAll(*output, 0755); err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+	}
+
+	status := 0
+
+	for _, file := range files {
+		if convertFile(e, file, *output, options) != 0 {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+// Returns the format of the -style flag.
+func sgfStyle(style string) (sgf.SgfFormat, error) {
+	format, ok := fmtStyles[style]
+	if !ok {
+		return format, errors.New(fmt.Sprintf("unknown style %q", style))
+	}
+
+	return format, nil
+}
+
+// Returns the input and the output formats of the flags. The output format is taken from the extension of the output
+// file if it's not given.
+func convertFormats(from, to, output string) (*inputFormat, *outputFormat, error) {
+	var input *inputFormat
+	if from != "" {
+		for i := range inputFormats {
+			if inputFormats[i].name == from {
+				input = &inputFormats[i]
+			}
+		}
+
+		if input == nil {
+			return nil, nil, errors.New(fmt.Sprintf("unknown input format %q", from))
+		}
+	}
+
+	if to == "" && output != "" && !isDir(output) {
+		to = strings.ToLower(strings.TrimPrefix(filepath.Ext(output), "."))
+	}
+
+	if to == "" {
+		return nil, nil, errors.New("output format (-to) is not given")
+	}
+
+	for i := range outputFormats {
+		if outputFormats[i].name == to {
+			return input, &outputFormats[i], nil
+		}
+	}
+
+	return nil, nil, errors.New(fmt.Sprintf("unknown output format %q", to))
+}
+
+// Returns the extensions of the input format, or all the known extensions if the format is nil.
+func inputExtensions(format *inputFormat) []string {
+	if format != nil {
+		return format.extensions
+	}
+
+	extensions := []string{}
+	for _, f := range inputFormats {
+		extensions = append(extensions, f.extensions...)
+	}
+
+	return extensions
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Converts the file and writes the result. Output is a directory, a file or empty for the standard output. Returns
+// the exit status.
+func convertFile(e *env, file inputFile, output string, options convertOptions) int {
+	data, err := readInput(e, file.path)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	collection, err := readCollection(file.path, data, options)
+	if err != nil {
+		fmt.Fprintln(e.stderr, errorMessage(file.path, err))
+		return 1
+	}
+
+	result, err := options.to.write(collection, options)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "%s: %s\n", file.path, err)
+		return 1
+	}
+
+	if output == "" {
+		e.stdout.Write(result)
+		return 0
+	}
+
+	if isDir(output) {
+		rel := strings.TrimSuffix(file.rel, filepath.Ext(file.rel)) + options.to.extension
+		output = filepath.Join(output, rel)
+
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+	}
+
+	if err := ioutil.WriteFile(output, result, 0644); err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// Decodes and parses the input data.
+func readCollection(name string, data []byte, options convertOptions) (*sgf.Collection, error) {
+	collection, _, err := readCollectionWithLayout(name, data, options)
+	return collection, err
+}
+
+// Decodes and parses the input data. Layout of SGF input is returned for the positions of the Nodes, it is nil for
+// the other formats.
+func readCollectionWithLayout(name string, data []byte, options convertOptions) (*sgf.Collection, *sgf.Layout, error) {
+	format := options.from
+	if format == nil {
+		if format = detectFormat(name, data); format == nil {
+			return nil, nil, errors.New("unknown input format, use -from")
+		}
+	}
+
+	charset := options.inputCharset
+	if charset == "" {
+		charset = "UTF-8"
+		if format.name == "sgf" {
+			charset = sgfCharset(data)
+		}
+	}
+
+	text, err := decodeCharset(data, charset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var collection *sgf.Collection
+	var layout *sgf.Layout
+
+	if format.name == "sgf" {
+		collection, layout, err = sgf.ParseSgfWithLayout(text)
+	} else {
+		collection, err = format.parse(text)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !collection.Valid() {
+		return nil, nil, errors.New("collection is empty")
+	}
+
+	return collection, layout, nil
+}
+
+// Detects the format of the file from its extension, or from the content if the extension is not known. Returns nil
+// if the format can't be detected.
+func detectFormat(name string, data []byte) *inputFormat {
+	for i, format := range inputFormats {
+		if hasExtension(name, format.extensions) {
+			return &inputFormats[i]
+		}
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	content := bytes.TrimLeft(data, " \t\r\n")
+
+	name = ""
+	switch {
+	case bytes.HasPrefix(content, []byte("(")):
+		name = "sgf"
+	case bytes.Contains(bytes.ToLower(content), []byte("[header]")):
+		name = "ugf"
+	case bytes.HasPrefix(content, []byte("{")) || bytes.HasPrefix(content, []byte("[")):
+		name = "json"
+	case bytes.HasPrefix(content, []byte("<")):
+		name = "xml"
+	case bytes.Contains(content, []byte(`\HS`)) || bytes.Contains(content, []byte(`\GS`)):
+		name = "gib"
+	case isNgf(data):
+		name = "ngf"
+	}
+
+	for i, format := range inputFormats {
+		if format.name == name {
+			return &inputFormats[i]
+		}
+	}
+
+	return nil
+}
+
+// Checks if the data looks like NGF: the board size is given alone on the second line.
+func isNgf(data []byte) bool {
+	lines := bytes.SplitN(data, []byte("\n"), 3)
+	if len(lines) < 3 {
+		return false
+	}
+
+	_, err := strconv.Atoi(string(bytes.TrimSpace(lines[1])))
+	return err == nil
+}
+
+//
+// Output formats
+//
+
+// Writes the collection as SGF in the charset of the options. Without a charset the output is UTF-8, and CA
+// properties naming another charset are changed to UTF-8. The collection itself is not changed.
+func writeSgf(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	collection = copyRoots(collection)
+
+	for _, gameTree := range collection.GameTrees {
+		root := gameTree.Nodes[0]
+
+		if options.charset != "" {
+			setProperty(root, "CA", options.charset)
+		} else if property := root.Property("CA"); property != nil && len(property.Values) > 0 {
+			if name, _ := normalizeCharset(property.Values[0]); name != charsetUTF8 {
+				setProperty(root, "CA", "UTF-8")
+			}
+		}
+	}
+
+	text := formatCollection(collection, options.format)
+
+	if options.charset == "" {
+		return []byte(text), nil
+	}
+
+	return encodeCharset(text, options.charset)
+}
+
+// Returns a copy of the collection sharing everything but the root Nodes, which can then be changed.
+func copyRoots(collection *sgf.Collection) *sgf.Collection {
+	copied := &sgf.Collection{}
+
+	for _, gameTree := range collection.GameTrees {
+		nodes := append([]*sgf.Node{}, gameTree.Nodes...)
+
+		if len(nodes) > 0 {
+			root := &sgf.Node{}
+			for _, property := range nodes[0].Properties {
+				root.NewProperty(property.Ident, append([]string{}, property.Values...)...)
+			}
+
+			nodes[0] = root
+		}
+
+		copied.AddGameTree(&sgf.GameTree{Nodes: nodes, GameTrees: gameTree.GameTrees})
+	}
+
+	return copied
+}
+
+// Sets the only value of the property, adding the property if needed.
+func setProperty(node *sgf.Node, ident, value string) {
+	if property := node.Property(ident); property != nil {
+		property.Values = []string{value}
+		return
+	}
+
+	node.NewProperty(ident, value)
+}
+
+func writeJSON(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	data, err := collection.JSON(sgf.JSONOptions{Typed: options.typed, Flat: options.flat, Indent: "  "})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+func writeXML(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	return xmlsgf.XML(collection, "  ")
+}
+
+func writeGib(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	gameTree, err := selectedGame(collection, options)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := gib.Gib(&sgf.Collection{GameTrees: []*sgf.GameTree{gameTree}})
+	return []byte(data), err
+}
+
+func writeUgf(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	gameTree, err := selectedGame(collection, options)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ugf.Ugf(&sgf.Collection{GameTrees: []*sgf.GameTree{gameTree}})
+	return []byte(data), err
+}
+
+func writeDiagram(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	path, err := selectedPath(collection, options)
+	if err != nil {
+		return nil, err
+	}
+
+	diagramOptions := sgf.DefaultDiagramOptions
+	if options.unicode {
+		diagramOptions.Style = sgf.DiagramUnicode
+	}
+
+	diagram, err := sgf.Diagram(path, diagramOptions)
+	return []byte(diagram), err
+}
+
+func writeSVG(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	path, err := selectedPath(collection, options)
+	if err != nil {
+		return nil, err
+	}
+
+	svgOptions := svg.DefaultOptions
+	svgOptions.PointSize = float64(options.size)
+
+	image, err := svg.Render(path, svgOptions)
+	return []byte(image), err
+}
+
+func writePNG(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	path, err := selectedPath(collection, options)
+	if err != nil {
+		return nil, err
+	}
+
+	rasterOptions := raster.DefaultOptions
+	rasterOptions.PointSize = options.size
+
+	var buffer bytes.Buffer
+	err = raster.PNG(&buffer, path, rasterOptions)
+
+	return buffer.Bytes(), err
+}
+
+func writeGIF(collection *sgf.Collection, options convertOptions) ([]byte, error) {
+	path, err := selectedPath(collection, options)
+	if err != nil {
+		return nil, err
+	}
+
+	rasterOptions := raster.DefaultOptions
+	rasterOptions.PointSize = options.size
+
+	var buffer bytes.Buffer
+	err = raster.GIF(&buffer, path, rasterOptions, raster.DefaultGIFOptions)
+
+	return buffer.Bytes(), err
+}
+
+// Returns the GameTree selected with the -game flag.
+func selectedGame(collection *sgf.Collection, options convertOptions) (*sgf.GameTree, error) {
+	if options.game < 1 || options.game > len(collection.GameTrees) {
+		return nil, errors.New(fmt.Sprintf("game %d not found, collection has %d games", options.game, len(collection.GameTrees)))
+	}
+
+	return collection.GameTrees[options.game-1], nil
+}
+
+// Returns the main line of the selected GameTree up to the move selected with the -move flag.
+func selectedPath(collection *sgf.Collection, options convertOptions) ([]*sgf.Node, error) {
+	gameTree, err := selectedGame(collection, options)
+	if err != nil {
+		return nil, err
+	}
+
+	path := gameTree.MainLine()
+	if options.move < 0 {
+		return path, nil
+	}
+
+	moves := 0
+	for i, node := range path {
+		if node.Property("B") != nil || node.Property("W") != nil {
+			moves++
+		}
+
+		if moves == options.move {
+			return path[:i+1], nil
+		}
+	}
+
+	if options.move == 0 {
+		return path[:1], nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("move %d not found, main line has %d moves", options.move, moves))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+func TestConvert(t *testing.T) {
+	var tests = []struct {
+		stdin  string
+		args   []string
+		output string
+	}{
+		{"{\"gameTrees\": [{\"nodes\": [{\"properties\": [{\"ident\": \"FF\", \"values\": [\"4\"]}]}]}]}", []string{"convert", "-to", "sgf"}, "(;FF[4])\n"},
+		{"<collection><gametree><node><property ident=\"C\"><value>x</value></property></node></gametree></collection>",
+			[]string{"convert", "-to", "sgf"}, "(;C[x])\n"},
+		{"(;SZ[5]AB[bb];W[cc];B[dd])", []string{"convert", "-to", "txt", "-move", "1"},
+			"  A B C D E\n5 . . . . .\n4 . X . . .\n3 . .(O). .\n2 . . . . .\n1 . . . . .\n"},
+		{"(;FF[4]C[\xe4]CA[ISO-8859-1])", []string{"convert", "-to", "sgf", "-charset", "UTF-8"}, "(;FF[4]C[ä]CA[UTF-8])\n"},
+		{"(;FF[4]C[ä])", []string{"convert", "-to", "sgf", "-charset", "latin1", "-style", "compact"}, "(;FF[4]C[\xe4]CA[latin1])\n"},
+		{"(;CA[ISO-8859-1]PB[J\xe4rvi];B[aa])", []string{"convert", "-to", "sgf", "-style", "compact"}, "(;CA[UTF-8]PB[Järvi];B[aa])\n"},
+		{"(;CA[utf8]C[ä])", []string{"convert", "-to", "sgf", "-style", "compact"}, "(;CA[utf8]C[ä])\n"},
+	}
+
+	for _, test := range tests {
+		status, stdout, stderr := runCommand(test.stdin, test.args...)
+
+		if status != 0 || stdout != test.output {
+			t.Errorf("%v with %q mismatch. Wanted: %q, got: %d %q %q", test.args, test.stdin, test.output, status, stdout, stderr)
+		}
+	}
+
+	// Round trip through the other formats
+	for _, to := range []string{"json", "xml"} {
+		sgfData := "(;FF[4]GM[1]C[a\\]b]\n ;B[aa]\n    (;W[bb])\n    (;W[cc]))\n"
+
+		_, converted, _ := runCommand(sgfData, "convert", "-to", to)
+		if _, stdout, _ := runCommand(converted, "convert", "-to", "sgf"); stdout != sgfData {
+			t.Errorf("Conversion to %s and back mismatch. Got: %q", to, stdout)
+		}
+	}
+
+	var errTests = []struct {
+		stdin string
+		args  []string
+	}{
+		{"(;FF[4])", []string{"convert"}},
+		{"(;FF[4])", []string{"convert", "-to", "ngf"}},
+		{"(;FF[4])", []string{"convert", "-to", "sgf", "-charset", "Shift_JIS"}},
+		{"(;FF[3])", []string{"convert", "-to", "sgf", "-ff", "4"}},
+		{"(;FF[4]C[ä])", []string{"convert", "-to", "sgf", "-charset", "ASCII"}},
+		{"(;FF[4];B[aa])", []string{"convert", "-to", "txt", "-move", "2"}},
+		{"(;FF[4]", []string{"convert", "-to", "json"}},
+		{"foo", []string{"convert", "-to", "sgf"}},
+	}
+
+	for _, test := range errTests {
+		if status, _, _ := runCommand(test.stdin, test.args...); status == 0 {
+			t.Errorf("%v with %q did not fail.", test.args, test.stdin)
+		}
+	}
+
+	if _, _, stderr := runCommand("foo", "convert", "-to", "sgf"); !strings.Contains(stderr, "unknown input format, use -from") {
+		t.Errorf("convert of an unknown format mismatch. Got: %q", stderr)
+	}
+}
+
+func TestWriteSgf(t *testing.T) {
+	collection, _ := sgf.ParseSgf("(;CA[latin1]FF[3];B[aa])")
+
+	data, err := writeSgf(collection, convertOptions{format: fmtStyles["compact"]})
+	if err != nil || string(data) != "(;CA[UTF-8]FF[3];B[aa])\n" {
+		t.Errorf("writeSgf() mismatch. Got: %q %v", data, err)
+	}
+
+	if s := collection.Sgf(sgf.SgfFormat{}); s != "(;CA[latin1]FF[3];B[aa])" {
+		t.Errorf("writeSgf() changed the collection: %s", s)
+	}
+}
+
+func TestConvertDirectory(t *testing.T) {
+	gibData, err := ioutil.ReadFile(filepath.Join("..", "..", "gib", "testdata", "jubango.gib"))
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %s", err)
+	}
+
+	dir := tempDir(t, map[string]string{
+		"in/a.sgf":       "(;FF[4]SZ[9];B[cc])",
+		"in/b/game.gib":  string(gibData),
+		"in/b/notes.txt": "not a game",
+	})
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out")
+	if status, _, stderr := runCommand("", "convert", "-to", "png", "-size", "8", "-o", out, filepath.Join(dir, "in")); status != 0 {
+		t.Fatalf("convert returned %d: %s", status, stderr)
+	}
+
+	for _, name := range []string{"a.png", filepath.Join("b", "game.png")} {
+		data, err := ioutil.ReadFile(filepath.Join(out, name))
+		if err != nil || !strings.HasPrefix(string(data), "\x89PNG") {
+			t.Errorf("convert did not write %s.", name)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "b", "notes.png")); err == nil {
+		t.Errorf("convert converted a file of an unknown format.")
+	}
+
+	// Output format from the extension of the output file
+	file := filepath.Join(dir, "game.sgf")
+	if status, _, stderr := runCommand("", "convert", "-o", file, filepath.Join(dir, "in", "b", "game.gib")); status != 0 {
+		t.Fatalf("convert returned %d: %s", status, stderr)
+	}
+
+	if data, _ := ioutil.ReadFile(file); !strings.Contains(string(data), "PB[") {
+		t.Errorf("convert did not convert GIB to SGF. Got: %q", data)
+	}
+
+	if status, _, _ := runCommand("", "convert", "-to", "sgf", filepath.Join(dir, "in")); status != 2 {
+		t.Errorf("convert of a directory without -o did not fail.")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	var tests = []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{"a.SGF", "", "sgf"},
+		{"a.ugi", "", "ugf"},
+		{"a", "\xef\xbb\xbf  (;FF[4])", "sgf"},
+		{"a", "\n{}", "json"},
+		{"a", "<?xml?>", "xml"},
+		{"a", "\\HS\n\\HE", "gib"},
+		{"a", "[Header]\nTitle=x", "ugf"},
+		{"a", "Game\n19\n", "ngf"},
+		{"a", "\n19\nWhite\n", "ngf"},
+		{"a", "Game\nnineteen\n", ""},
+		{"a", "foo", ""},
+	}
+
+	for _, test := range tests {
+		name := ""
+		if format := detectFormat(test.name, []byte(test.data)); format != nil {
+			name = format.name
+		}
+
+		if name != test.format {
+			t.Errorf("detectFormat(%s, %q) mismatch. Wanted: %s, got: %s", test.name, test.data, test.format, name)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+func runDiff(e *env, args []string) int {
+	flags := newFlagSet(e, "diff", "[flags] old new")
+	lines := flags.Bool("lines", false, "show the differences of the formatted files as a unified diff instead")
+	style := flags.String("style", "default", "SGF output format of -lines: default, cgoban or compact")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := sgfStyle(*style)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	collections := [2]*sgf.Collection{}
+	for i, name := range flags.Args() {
+		data, err := readInput(e, name)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 2
+		}
+
+		if collections[i], err = readCollection(name, data, convertOptions{}); err != nil {
+			fmt.Fprintln(e.stderr, errorMessage(name, err))
+			return 2
+		}
+	}
+
+	// Exit status as in diff: 0 if the collections are the same, 1 if they differ and 2 on errors
+	if *lines {
+		diff := lineDiff(flags.Arg(0), flags.Arg(1), formatCollection(collections[0], format),
+			formatCollection(collections[1], format))
+		fmt.Fprint(e.stdout, diff)
+
+		if diff != "" {
+			return 1
+		}
+
+		return 0
+	}
+
+	changes := sgf.Diff(collections[0], collections[1])
+	for _, change := range changes {
+		fmt.Fprintln(e.stdout, change)
+	}
+
+	if len(changes) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// Number of unchanged lines shown around the changes.
+const diffContext = 3
+
+// Line of a diff: ' ' for unchanged, '-' for removed and '+' for added lines.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// Returns the differences of the texts a and b in the unified diff format, or an empty string if the texts are equal.
+func lineDiff(nameA, nameB, a, b string) string {
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var buffer bytes.Buffer
+
+	for start := 0; start < len(lines); {
+		// Find the next change
+		for start < len(lines) && lines[start].op == ' ' {
+			start++
+		}
+
+		if start == len(lines) {
+			break
+		}
+
+		// Extend the hunk until there are more than two contexts worth of unchanged lines
+		end := start
+		for unchanged := 0; end < len(lines) && unchanged <= 2*diffContext; end++ {
+			if lines[end].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+
+		for end > start && lines[end-1].op == ' ' {
+			end--
+		}
+
+		first, last := max(start-diffContext, 0), min(end+diffContext, len(lines))
+
+		if buffer.Len() == 0 {
+			buffer.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", nameA, nameB))
+		}
+
+		writeHunk(&buffer, lines, first, last)
+		start = last
+	}
+
+	return buffer.String()
+}
+
+func writeHunk(buffer *bytes.Buffer, lines []diffLine, first, last int) {
+	// Line numbers of the hunk start from 1
+	lineA, lineB := 1, 1
+	for _, line := range lines[:first] {
+		if line.op != '+' {
+			lineA++
+		}
+		if line.op != '-' {
+			lineB++
+		}
+	}
+
+	countA, countB := 0, 0
+	for _, line := range lines[first:last] {
+		if line.op != '+' {
+			countA++
+		}
+		if line.op != '-' {
+			countB++
+		}
+	}
+
+	buffer.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(lineA, countA), hunkRange(lineB, countB)))
+
+	for _, line := range lines[first:last] {
+		buffer.WriteByte(line.op)
+		buffer.WriteString(line.text)
+
+		if !strings.HasSuffix(line.text, "\n") {
+			buffer.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(line, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line-1)
+	case 1:
+		return fmt.Sprint(line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// Splits the text to lines keeping the line breaks.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// Returns the lines of a and b aligned by their longest common subsequence.
+func diffLines(a, b []string) []diffLine {
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lengths[i+1][j] >= lengths[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+
+	return lines
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"a.sgf": "(;PB[Lee];B[pd](;W[dd])(;W[dp]))",
+		"b.sgf": "(;PB[Lee Sedol]\n;B[pd]\n(;W[dp]C[good])\n(;W[dd]))",
+		"c.sgf": "(;PB[Lee]\n;B[pd](;W[dd])(;W[dp]))\n",
+	})
+	defer os.RemoveAll(dir)
+
+	a, b, c := filepath.Join(dir, "a.sgf"), filepath.Join(dir, "b.sgf"), filepath.Join(dir, "c.sgf")
+
+	wanted := "game 1, node 0: PB changed: \"Lee\" -> \"Lee Sedol\"\n" +
+		"game 1, node 1 B[pd]: variations reordered: W[dd] W[dp] -> W[dp] W[dd]\n" +
+		"game 1, node 2 W[dp]: C added: \"good\"\n"
+	if status, stdout, _ := runCommand("", "diff", a, b); status != 1 || stdout != wanted {
+		t.Errorf("diff mismatch. Got: %d %q", status, stdout)
+	}
+
+	if status, stdout, _ := runCommand("", "diff", a, c); status != 0 || stdout != "" {
+		t.Errorf("diff of equal collections mismatch. Got: %d %q", status, stdout)
+	}
+
+	wanted = "--- " + a + "\n+++ " + b + "\n@@ -1 +1 @@\n-(;PB[Lee];B[pd](;W[dd])(;W[dp]))\n+(;PB[Lee Sedol];B[pd](;W[dp]C[good])(;W[dd]))\n"
+	if status, stdout, _ := runCommand("", "diff", "-lines", "-style", "compact", a, b); status != 1 || stdout != wanted {
+		t.Errorf("diff -lines mismatch. Got: %d %q", status, stdout)
+	}
+
+	if status, _, _ := runCommand("", "diff", a); status != 2 {
+		t.Errorf("diff of one file returned %d", status)
+	}
+}
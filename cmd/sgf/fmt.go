@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+// Base formats selected with the -style flag.
+var fmtStyles = map[string]sgf.SgfFormat{
+	"default": sgf.DefaultSgfFormat,
+	"cgoban":  sgf.CGobanSgfFormat,
+	"compact": sgf.NoNewLinesSgfFormat,
+}
+
+// Options of the fmt command.
+type fmtOptions struct {
+	format sgf.SgfFormat
+	list   bool // list the files whose formatting differs
+	write  bool // write the result to the file
+	diff   bool // print the differences
+}
+
+func runFmt(e *env, args []string) int {
+	flags := newFlagSet(e, "fmt", "[flags] [path ...]")
+	list := flags.Bool("l", false, "list files whose formatting differs")
+	write := flags.Bool("w", false, "write result to the file instead of the standard output")
+	diff := flags.Bool("d", false, "display diffs instead of rewriting files")
+	style := flags.String("style", "default", "base format: default, cgoban or compact")
+	indent := flags.Int("indent", 0, "number of spaces per indentation level")
+	tabs := flags.Bool("tabs", false, "indent with tabs")
+	width := flags.Int("width", 0, "wrap lines longer than this, 0 = no limit")
+	moves := flags.Int("moves", 0, "number of moves per line, 0 = one node per line")
+	order := flags.String("order", "", "comma separated idents of the properties written first, or \"canonical\"")
+	sortProperties := flags.Bool("sort", false, "write the rest of the properties in alphabetical order")
+	crlf := flags.Bool("crlf", false, "use CRLF line endings")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := sgfStyle(*style)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	// Flags override the style only when given
+	set := setFlags(flags)
+	if set["indent"] {
+		format.IndentationLevel = *indent
+	}
+	if set["tabs"] {
+		format.IndentWithTabs = *tabs
+	}
+	if set["width"] {
+		format.MaxLineWidth = *width
+	}
+	if set["moves"] {
+		format.MovesPerLine = *moves
+	}
+	if set["order"] {
+		format.PropertyOrder = propertyOrder(*order)
+	}
+	if set["sort"] {
+		format.SortProperties = *sortProperties
+	}
+	if set["crlf"] {
+		format.CRLF = *crlf
+	}
+
+	options := fmtOptions{format, *list, *write, *diff}
+
+	if flags.NArg() == 0 {
+		if options.write {
+			fmt.Fprintf(e.stderr, "sgf: cannot use -w with standard input\n")
+			return 2
+		}
+
+		return fmtFile(e, stdinName, options)
+	}
+
+	files, err := sgfFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	status := 0
+
+	for _, file := range files {
+		if fmtFile(e, file, options) != 0 {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+// Parses the value of the -order flag.
+func propertyOrder(value string) []string {
+	switch value {
+	case "":
+		return nil
+	case "canonical":
+		return sgf.CanonicalPropertyOrder
+	}
+
+	return strings.Split(value, ",")
+}
+
+// Formats the file and outputs the result as the options say. Returns the exit status.
+func fmtFile(e *env, name string, options fmtOptions) int {
+	data, err := readInput(e, name)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	text, charset, err := decodeSgf(data)
+	if err != nil {
+		fmt.Fprintln(e.stderr, errorMessage(name, err))
+		return 1
+	}
+
+	collection, err := sgf.ParseSgf(text)
+	if err != nil {
+		fmt.Fprintln(e.stderr, errorMessage(name, err))
+		return 1
+	}
+
+	if !collection.Valid() {
+		fmt.Fprintf(e.stderr, "%s: collection is empty\n", name)
+		return 1
+	}
+
+	// The result is written in the charset of the file
+	formatted := formatCollection(collection, options.format)
+	result, err := encodeCharset(formatted, charset)
+	if err != nil {
+		fmt.Fprintln(e.stderr, errorMessage(name, err))
+		return 1
+	}
+
+	changed := !bytes.Equal(result, data)
+
+	if options.list && changed {
+		fmt.Fprintln(e.stdout, name)
+	}
+
+	if options.write && changed {
+		info, err := os.Stat(name)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+
+		if err := ioutil.WriteFile(name, result, info.Mode().Perm()); err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+	}
+
+	if options.diff && changed {
+		fmt.Fprint(e.stdout, lineDiff(name+".orig", name, text, formatted))
+	}
+
+	if !options.list && !options.write && !options.diff {
+		e.stdout.Write(result)
+	}
+
+	return 0
+}
+
+// Formats the collection with each top-level GameTree on its own line when the format puts GameTrees on their own
+// lines. The result ends with a line break.
+func formatCollection(collection *sgf.Collection, format sgf.SgfFormat) string {
+	lineBreak := "\n"
+	if format.CRLF {
+		lineBreak = "\r\n"
+	}
+
+	var buffer bytes.Buffer
+
+	for i, gameTree := range collection.GameTrees {
+		if i > 0 && format.NewLineBetweenGameTrees {
+			buffer.WriteString(lineBreak)
+		}
+
+		buffer.WriteString((&sgf.Collection{GameTrees: []*sgf.GameTree{gameTree}}).Sgf(format))
+	}
+
+	buffer.WriteString(lineBreak)
+
+	return buffer.String()
+}
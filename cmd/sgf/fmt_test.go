@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFmt(t *testing.T) {
+	var tests = []struct {
+		stdin  string
+		args   []string
+		output string
+	}{
+		{"(;FF[4]GM[1];B[aa];W[bb])", []string{"fmt"}, "(;FF[4]GM[1]\n ;B[aa]\n ;W[bb])\n"},
+		{"(;GM[1]FF[4];B[aa];W[bb])(;FF[4])", []string{"fmt", "-style", "compact"}, "(;GM[1]FF[4];B[aa];W[bb])(;FF[4])\n"},
+		{"(;GM[1]FF[4];B[aa];W[bb])(;FF[4])", []string{"fmt", "-style", "cgoban"}, "(;FF[4]GM[1]\n ;B[aa];W[bb])\n(;FF[4])\n"},
+		{"(;FF[4]GM[1];B[aa](;W[bb])(;W[cc]))", []string{"fmt", "-indent", "2", "-crlf"},
+			"(;FF[4]GM[1]\r\n ;B[aa]\r\n  (;W[bb])\r\n  (;W[cc]))\r\n"},
+		{"(;C[x]FF[4]GM[1]AP[a:1])", []string{"fmt", "-order", "GM,FF", "-sort"}, "(;GM[1]FF[4]AP[a:1]C[x])\n"},
+	}
+
+	for _, test := range tests {
+		status, stdout, stderr := runCommand(test.stdin, test.args...)
+
+		if status != 0 || stdout != test.output {
+			t.Errorf("%v with %q mismatch. Wanted: %q, got: %d %q %q", test.args, test.stdin, test.output, status, stdout, stderr)
+		}
+	}
+
+	if status, _, stderr := runCommand("(;FF[4]\n  ;B[aa]x)", "fmt"); status != 1 || stderr != "<standard input>:2:9: Invalid character x\n" {
+		t.Errorf("fmt of an invalid file mismatch. Got: %d %q", status, stderr)
+	}
+
+	if status, _, _ := runCommand("(;)", "fmt", "-style", "foo"); status != 2 {
+		t.Errorf("fmt accepted an unknown style.")
+	}
+}
+
+func TestFmtFiles(t *testing.T) {
+	formatted := "(;FF[4]\n ;B[aa])\n"
+	dir := tempDir(t, map[string]string{
+		"a.sgf":   "(;FF[4];B[aa])",
+		"b/c.sgf": formatted,
+	})
+	defer os.RemoveAll(dir)
+
+	a := filepath.Join(dir, "a.sgf")
+
+	status, stdout, _ := runCommand("", "fmt", "-l", dir)
+	if status != 0 || stdout != a+"\n" {
+		t.Errorf("fmt -l mismatch. Got: %d %q", status, stdout)
+	}
+
+	wantedDiff := "--- " + a + ".orig\n+++ " + a + "\n@@ -1 +1,2 @@\n-(;FF[4];B[aa])\n\\ No newline at end of file\n+(;FF[4]\n+ ;B[aa])\n"
+	if _, stdout, _ := runCommand("", "fmt", "-d", dir); stdout != wantedDiff {
+		t.Errorf("fmt -d mismatch. Got: %q", stdout)
+	}
+
+	if status, stdout, _ := runCommand("", "fmt", "-w", dir); status != 0 || stdout != "" {
+		t.Errorf("fmt -w mismatch. Got: %d %q", status, stdout)
+	}
+
+	if data, _ := ioutil.ReadFile(a); string(data) != formatted {
+		t.Errorf("fmt -w did not format the file. Got: %q", data)
+	}
+
+	if _, stdout, _ := runCommand("", "fmt", "-l", dir); stdout != "" {
+		t.Errorf("fmt -l listed formatted files: %q", stdout)
+	}
+}
+
+func TestFmtCharset(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"a.sgf": "(;FF[4]CA[ISO-8859-1]PB[J\xf6rg];B[aa])",
+		"b.sgf": "(;FF[4]CA[ISO-8859-1]PB[J\xf6rg]\n ;B[aa])\n",
+		"c.sgf": "(;FF[4]CA[Shift_JIS]PB[x];B[aa])",
+	})
+	defer os.RemoveAll(dir)
+
+	a, b, c := filepath.Join(dir, "a.sgf"), filepath.Join(dir, "b.sgf"), filepath.Join(dir, "c.sgf")
+
+	if status, stdout, stderr := runCommand("", "fmt", "-l", a, b); status != 0 || stdout != a+"\n" {
+		t.Errorf("fmt -l of ISO-8859-1 files mismatch. Got: %d %q %q", status, stdout, stderr)
+	}
+
+	if status, _, stderr := runCommand("", "fmt", "-w", a); status != 0 {
+		t.Fatalf("fmt -w returned %d: %s", status, stderr)
+	}
+
+	// The file is kept in its charset
+	if data, _ := ioutil.ReadFile(a); string(data) != "(;FF[4]CA[ISO-8859-1]PB[J\xf6rg]\n ;B[aa])\n" {
+		t.Errorf("fmt -w of an ISO-8859-1 file mismatch. Got: %q", data)
+	}
+
+	if status, _, _ := runCommand("", "fmt", "-w", c); status != 1 {
+		t.Errorf("fmt -w of a file in an unsupported charset returned %d", status)
+	}
+
+	if data, _ := ioutil.ReadFile(c); string(data) != "(;FF[4]CA[Shift_JIS]PB[x];B[aa])" {
+		t.Errorf("fmt -w changed a file in an unsupported charset. Got: %q", data)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	b := "1\n2\nx\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n15\n"
+
+	wanted := "--- a\n+++ b\n" +
+		"@@ -1,5 +1,6 @@\n 1\n 2\n+x\n 3\n 4\n 5\n" +
+		"@@ -11,5 +12,4 @@\n 11\n 12\n 13\n-14\n 15\n"
+
+	if diff := lineDiff("a", "b", a, b); diff != wanted {
+		t.Errorf("lineDiff() mismatch. Wanted: %q, got: %q", wanted, diff)
+	}
+
+	if diff := lineDiff("a", "b", a, a); diff != "" {
+		t.Errorf("lineDiff() of equal texts returned %q", diff)
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Command sgf checks, formats, converts and inspects SGF files.
+
+Usage:
+
+	sgf <command> [flags] [path ...]
+
+The commands are:
+
+	validate   report syntax errors and problems found by Collection.Validate
+	fmt        format SGF files
+	convert    convert between SGF, JSON, XML, GIB, NGF and UGF and draw diagrams and images
+	stats      report games, nodes, properties, board sizes, results and problems of collections
+	split      write each game of collections to its own file named by a template, e.g. "{DT}-{PB}-vs-{PW}.sgf"
+	join       join the games of many files to one collection
+	query      find nodes matching a query, e.g. 'root.PB ~ "Lee Sedol" and RE startswith "W+"'
+	diff       report added, removed and reordered variations and changed properties of two collections
+	merge      three-way merge of variations and properties of collections, also as a git merge driver
+
+Paths may be files or directories. Directories are searched recursively for files with the .sgf extension, or with
+the extension of any supported format for convert, stats, join and query. Without paths the standard input is used.
+
+Input and output charsets are limited to UTF-8, ISO-8859-1 and US-ASCII. GIB and NGF files, and SGF files with CA,
+are often in EUC-KR, GBK or Shift_JIS; convert them to UTF-8 first, e.g. with "iconv -f EUC-KR -t UTF-8", and give
+-input-charset UTF-8 for SGF files whose CA still names the old charset.
+
+Problems are reported as "file:line:column: message". The exit status is 1 if any problems were found and 2 if the
+command line was invalid.
+
+The merge command can be used as a git merge driver for SGF files, with -comments to join the comments written on
+both sides:
+
+	git config merge.sgf.driver "sgf merge -git -comments %O %A %B"
+	echo "*.sgf merge=sgf" >> .gitattributes
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+// Name used for the standard input in the messages.
+const stdinName = "<standard input>"
+
+// Standard streams of a command, replaced in the tests.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name  string
+	short string
+	run   func(e *env, args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"validate", "report syntax errors and semantic problems", runValidate},
+		{"fmt", "format SGF files", runFmt},
+		{"convert", "convert between SGF and other formats", runConvert},
+		{"stats", "report statistics of collections", runStats},
+		{"split", "write each game of collections to its own file", runSplit},
+		{"join", "join games of many files to one collection", runJoin},
+		{"query", "find nodes matching a query", runQuery},
+		{"diff", "report the structural differences of two collections", runDiff},
+		{"merge", "three-way merge of collections", runMerge},
+	}
+}
+
+func main() {
+	os.Exit(run(&env{os.Stdin, os.Stdout, os.Stderr}, os.Args[1:]))
+}
+
+func run(e *env, args []string) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(e, args[1:])
+		}
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(e.stdout)
+		return 0
+	}
+
+	fmt.Fprintf(e.stderr, "sgf: unknown command %q\n", args[0])
+	usage(e.stderr)
+
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: sgf <command> [flags] [path ...]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.short)
+	}
+}
+
+// Creates the flag set of a command. Errors and usage are written to the standard error of the environment.
+func newFlagSet(e *env, name, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: sgf %s %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// Returns the names of the set flags.
+func setFlags(flags *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	return set
+}
+
+// File found by findFiles.
+type inputFile struct {
+	path string
+	rel  string // path relative to the directory given on the command line, base name for files given directly
+}
+
+// Returns the files of the paths. Directories are searched recursively for files with the extensions, other paths are
+// returned as is.
+func findFiles(paths []string, extensions ...string) ([]inputFile, error) {
+	files := []inputFile{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, inputFile{path, filepath.Base(path)})
+			continue
+		}
+
+		err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() || !hasExtension(file, extensions) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(path, file)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, inputFile{file, rel})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// Returns the SGF files of the paths, see findFiles.
+func sgfFiles(paths []string) ([]string, error) {
+	files, err := findFiles(paths, ".sgf")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.path
+	}
+
+	return names, nil
+}
+
+// Check if the file has one of the extensions, ignoring case.
+func hasExtension(file string, extensions []string) bool {
+	for _, extension := range extensions {
+		if strings.EqualFold(filepath.Ext(file), extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reads the file, or the standard input if the name is stdinName.
+func readInput(e *env, name string) ([]byte, error) {
+	if name == stdinName {
+		return ioutil.ReadAll(e.stdin)
+	}
+
+	return ioutil.ReadFile(name)
+}
+
+// Returns the message of an error in the file. Syntax errors are prefixed with their position.
+func errorMessage(name string, err error) string {
+	if syntaxError, ok := err.(*sgf.SyntaxError); ok {
+		return fmt.Sprintf("%s:%d:%d: %s", name, syntaxError.Line, syntaxError.Column, syntaxError.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", name, err)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/toikarin/sgf"
+)
+
+func runMerge(e *env, args []string) int {
+	flags := newFlagSet(e, "merge", "[flags] base ours theirs")
+	output := flags.String("o", "", "output file, standard output by default")
+	driver := flags.Bool("git", false, "git merge driver mode: write the result over ours")
+	comments := flags.Bool("comments", false, "join comments changed on both sides instead of reporting conflicts")
+	separator := flags.String("separator", sgf.DefaultMergeOptions.CommentSeparator, "separator of the joined comments")
+	style := flags.String("style", "default", "SGF output format: default, cgoban or compact")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := sgfStyle(*style)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	if flags.NArg() != 3 || (*driver && *output != "") {
+		flags.Usage()
+		return 2
+	}
+
+	collections := [3]*sgf.Collection{}
+	for i, name := range flags.Args() {
+		data, err := readInput(e, name)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 2
+		}
+
+		if collections[i], err = readCollection(name, data, convertOptions{}); err != nil {
+			fmt.Fprintln(e.stderr, errorMessage(name, err))
+			return 2
+		}
+	}
+
+	options := sgf.MergeOptions{ConcatenateComments: *comments, CommentSeparator: *separator}
+	merged, conflicts := sgf.Merge3(collections[0], collections[1], collections[2], options)
+
+	for _, conflict := range conflicts {
+		fmt.Fprintf(e.stderr, "%s: conflict: %s\n", flags.Arg(1), conflict)
+	}
+
+	if *driver {
+		// git passes the current version as ours and expects the result in its place
+		*output = flags.Arg(1)
+	}
+
+	result, err := writeSgf(merged, convertOptions{format: format})
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	if *output == "" {
+		e.stdout.Write(result)
+	} else if err := ioutil.WriteFile(*output, result, 0644); err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	// Exit status as in git merge-file: 1 if there were conflicts
+	if len(conflicts) > 0 {
+		return 1
+	}
+
+	return 0
+}
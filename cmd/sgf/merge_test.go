@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"base":   "(;PB[a]C[lecture];B[pd])",
+		"ours":   "(;PB[a]C[lecture\nours];B[pd];W[dd])",
+		"theirs": "(;PB[b]C[lecture\ntheirs];B[pd];W[dp])",
+	})
+	defer os.RemoveAll(dir)
+
+	base, ours, theirs := filepath.Join(dir, "base"), filepath.Join(dir, "ours"), filepath.Join(dir, "theirs")
+
+	status, stdout, stderr := runCommand("", "merge", "-style", "compact", base, ours, theirs)
+	if status != 1 || stdout != "(;PB[b]C[lecture\nours];B[pd](;W[dd])(;W[dp]))\n" ||
+		stderr != ours+": conflict: game 1, node 0: C: base \"lecture\", ours \"lecture\\nours\", theirs \"lecture\\ntheirs\"\n" {
+		t.Errorf("merge mismatch. Got: %d %q %q", status, stdout, stderr)
+	}
+
+	status, _, stderr = runCommand("", "merge", "-git", "-comments", "-separator", "\n--\n", "-style", "compact", base, ours, theirs)
+	if status != 0 || stderr != "" {
+		t.Fatalf("merge -git returned %d: %s", status, stderr)
+	}
+
+	wanted := "(;PB[b]C[lecture\nours\n--\ntheirs];B[pd](;W[dd])(;W[dp]))\n"
+	if data, err := ioutil.ReadFile(ours); err != nil || string(data) != wanted {
+		t.Errorf("merge -git mismatch. Got: %q", data)
+	}
+
+	if status, _, _ := runCommand("", "merge", base, ours); status != 2 {
+		t.Errorf("merge of two files returned %d", status)
+	}
+}
+
+func TestMergeCharset(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"base":   "(;CA[ISO-8859-1]C[F\xf6rel\xe4sning];B[pd])",
+		"ours":   "(;CA[ISO-8859-1]C[F\xf6rel\xe4sning];B[pd];W[dd]C[\xe5])",
+		"theirs": "(;CA[ISO-8859-1]PB[J\xe4rvi]C[F\xf6rel\xe4sning];B[pd])",
+	})
+	defer os.RemoveAll(dir)
+
+	base, ours, theirs := filepath.Join(dir, "base"), filepath.Join(dir, "ours"), filepath.Join(dir, "theirs")
+
+	if status, _, stderr := runCommand("", "merge", "-git", "-style", "compact", base, ours, theirs); status != 0 {
+		t.Fatalf("merge -git returned %d: %s", status, stderr)
+	}
+
+	wanted := "(;CA[UTF-8]C[Föreläsning]PB[Järvi];B[pd];W[dd]C[å])\n"
+	if data, err := ioutil.ReadFile(ours); err != nil || string(data) != wanted {
+		t.Errorf("merge -git of ISO-8859-1 files mismatch. Got: %q", data)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/toikarin/sgf"
+	"github.com/toikarin/sgf/query"
+)
+
+func runQuery(e *env, args []string) int {
+	flags := newFlagSet(e, "query", "[flags] query [path ...]")
+	filesOnly := flags.Bool("l", false, "list only the names of the files with matches")
+	count := flags.Bool("c", false, "print only the number of matches of each file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	q, err := query.Compile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	files := []inputFile{{stdinName, stdinName}}
+	if flags.NArg() > 1 {
+		if files, err = findFiles(flags.Args()[1:], inputExtensions(nil)...); err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 2
+		}
+	}
+
+	// Exit status as in grep: 0 if anything matched, 1 if nothing matched and 2 on errors
+	matched, failed := false, false
+
+	for _, file := range files {
+		data, err := readInput(e, file.path)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			failed = true
+			continue
+		}
+
+		collection, layout, err := readCollectionWithLayout(file.path, data, convertOptions{})
+		if err != nil {
+			fmt.Fprintln(e.stderr, errorMessage(file.path, err))
+			failed = true
+			continue
+		}
+
+		matches := q.Match(collection)
+		matched = matched || len(matches) > 0
+
+		switch {
+		case *filesOnly:
+			if len(matches) > 0 {
+				fmt.Fprintln(e.stdout, file.path)
+			}
+		case *count:
+			fmt.Fprintf(e.stdout, "%s:%d\n", file.path, len(matches))
+		default:
+			for _, match := range matches {
+				fmt.Fprintln(e.stdout, matchMessage(file.path, layout, match))
+			}
+		}
+	}
+
+	switch {
+	case failed:
+		return 2
+	case matched:
+		return 0
+	}
+
+	return 1
+}
+
+// Returns the position and the path of the matching Node, e.g. "file.sgf:3:2: game 1, node 12, variation 0.1: B[pd]".
+func matchMessage(name string, layout *sgf.Layout, match query.Match) string {
+	location := name
+	if line, column, ok := layout.NodePosition(match.Node()); ok {
+		location = fmt.Sprintf("%s:%d:%d", name, line, column)
+	}
+
+	path := fmt.Sprintf("game %d, node %d", match.Game+1, len(match.Path)-1)
+	if variations := match.VariationString(); variations != "" {
+		path += ", variation " + variations
+	}
+
+	moves := []string{}
+	for _, property := range match.Node().Properties {
+		if property.Ident == "B" || property.Ident == "W" {
+			moves = append(moves, fmt.Sprintf("%s[%s]", property.Ident, strings.Join(property.Values, "][")))
+		}
+	}
+
+	if len(moves) == 0 {
+		return fmt.Sprintf("%s: %s", location, path)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", location, path, strings.Join(moves, ""))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"a.sgf": "(;PB[Lee Sedol]RE[W+R]\n;B[pd]\n(;W[dd])\n(;W[dp]C[x]))",
+		"b.sgf": "(;PB[Ke Jie]RE[W+0.5];B[pd])",
+	})
+	defer os.RemoveAll(dir)
+
+	a, b := filepath.Join(dir, "a.sgf"), filepath.Join(dir, "b.sgf")
+
+	var tests = []struct {
+		args   []string
+		status int
+		stdout string
+	}{
+		{[]string{"query", `B = "pd"`, a}, 0, a + ":2:1: game 1, node 1: B[pd]\n"},
+		{[]string{"query", `root.PB ~ "Lee" and has W`, a}, 0,
+			a + ":3:2: game 1, node 2: W[dd]\n" + a + ":4:2: game 1, node 2, variation 1: W[dp]\n"},
+		{[]string{"query", `depth = 0 and RE startswith "W+"`, a, b}, 0,
+			a + ":1:2: game 1, node 0\n" + b + ":1:2: game 1, node 0\n"},
+		{[]string{"query", "-l", `has C`, dir}, 0, a + "\n"},
+		{[]string{"query", "-c", `has B or has W`, a, b}, 0, a + ":3\n" + b + ":1\n"},
+		{[]string{"query", `has XX`, a, b}, 1, ""},
+		{[]string{"query", `has`, a}, 2, ""},
+		{[]string{"query"}, 2, ""},
+		{[]string{"query", `has B`, filepath.Join(dir, "c.sgf")}, 2, ""},
+	}
+
+	for _, test := range tests {
+		status, stdout, _ := runCommand("", test.args...)
+		if status != test.status || stdout != test.stdout {
+			t.Errorf("%v mismatch. Wanted: %d %q, got: %d %q", test.args, test.status, test.stdout, status, stdout)
+		}
+	}
+
+	if status, stdout, _ := runCommand("(;B[aa];W[bb])", "query", `W = "bb"`); status != 0 || stdout != "<standard input>:1:8: game 1, node 1: W[bb]\n" {
+		t.Errorf("query of standard input mismatch. Got: %d %q", status, stdout)
+	}
+}
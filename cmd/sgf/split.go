@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+// Default file name template of split.
+const defaultSplitTemplate = "{name}-{n}.sgf"
+
+func runSplit(e *env, args []string) int {
+	flags := newFlagSet(e, "split", "[flags] [path ...]")
+	output := flags.String("o", ".", "output directory")
+	template := flags.String("template", defaultSplitTemplate,
+		"file name template: {n} is the number of the game, {name} the name of the input file and {XX} the value of game info property XX")
+	style := flags.String("style", "default", "SGF output format: default, cgoban or compact")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := sgfStyle(*style)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	files := []inputFile{{stdinName, "stdin"}}
+	if flags.NArg() > 0 {
+		if files, err = findFiles(flags.Args(), ".sgf"); err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	status := 0
+	written := map[string]bool{}
+
+	for _, file := range files {
+		data, err := readInput(e, file.path)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			status = 1
+			continue
+		}
+
+		collection, err := readCollection(file.path, data, convertOptions{})
+		if err != nil {
+			fmt.Fprintln(e.stderr, errorMessage(file.path, err))
+			status = 1
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file.rel), filepath.Ext(file.rel))
+
+		for i, gameTree := range collection.GameTrees {
+			path := uniquePath(filepath.Join(*output, gameFileName(*template, name, i+1, gameTree)), written)
+			written[path] = true
+
+			data, err := writeSgf(&sgf.Collection{GameTrees: []*sgf.GameTree{gameTree}}, convertOptions{format: format})
+			if err == nil {
+				err = ioutil.WriteFile(path, data, 0644)
+			}
+
+			if err != nil {
+				fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+				status = 1
+			}
+		}
+	}
+
+	return status
+}
+
+var templatePattern = regexp.MustCompile(`\{(n|name|[A-Z]+)\}`)
+
+// Characters replaced in file names.
+var fileNameReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_",
+	">", "_", "|", "_", "\n", " ", "\r", " ", "\t", " ")
+
+// Returns the file name of the GameTree expanded from the template. ".." directories, e.g. from a game info value
+// "..", are replaced with "_" so that the file stays inside the output directory.
+func gameFileName(template, name string, n int, gameTree *sgf.GameTree) string {
+	fileName := templatePattern.ReplaceAllStringFunc(template, func(field string) string {
+		field = field[1 : len(field)-1]
+
+		switch field {
+		case "n":
+			return strconv.Itoa(n)
+		case "name":
+			return name
+		}
+
+		return strings.TrimSpace(fileNameReplacer.Replace(gameInfo(gameTree, field)))
+	})
+
+	parts := strings.Split(filepath.ToSlash(fileName), "/")
+	for i, part := range parts {
+		if part == ".." {
+			parts[i] = "_"
+		}
+	}
+
+	return filepath.FromSlash(strings.Join(parts, "/"))
+}
+
+// Returns the decoded value of a game info property of the GameTree. Game info is searched from the main line.
+func gameInfo(gameTree *sgf.GameTree, ident string) string {
+	for _, node := range gameTree.MainLine() {
+		if property := node.Property(ident); property != nil && len(property.Values) > 0 {
+			return property.DecodedValues()[0]
+		}
+	}
+
+	return ""
+}
+
+// Returns the path, or if it's already written or exists, the path with a number added before the extension.
+func uniquePath(path string, written map[string]bool) string {
+	extension := filepath.Ext(path)
+	base := strings.TrimSuffix(path, extension)
+
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); !written[path] && os.IsNotExist(err) {
+			return path
+		}
+
+		path = fmt.Sprintf("%s-%d%s", base, i, extension)
+	}
+}
+
+func runJoin(e *env, args []string) int {
+	flags := newFlagSet(e, "join", "[flags] [path ...]")
+	output := flags.String("o", "", "output file, standard output by default")
+	style := flags.String("style", "default", "SGF output format: default, cgoban or compact")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := sgfStyle(*style)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 2
+	}
+
+	files := []inputFile{{stdinName, stdinName}}
+	if flags.NArg() > 0 {
+		if files, err = findFiles(flags.Args(), inputExtensions(nil)...); err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+	}
+
+	joined := &sgf.Collection{}
+
+	for _, file := range files {
+		data, err := readInput(e, file.path)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+
+		collection, err := readCollection(file.path, data, convertOptions{})
+		if err != nil {
+			fmt.Fprintln(e.stderr, errorMessage(file.path, err))
+			return 1
+		}
+
+		for _, gameTree := range collection.GameTrees {
+			joined.AddGameTree(gameTree)
+		}
+	}
+
+	if !joined.Valid() {
+		fmt.Fprintf(e.stderr, "sgf: no games to join\n")
+		return 1
+	}
+
+	result, err := writeSgf(joined, convertOptions{format: format})
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		e.stdout.Write(result)
+		return 0
+	}
+
+	if err := ioutil.WriteFile(*output, result, 0644); err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+func TestSplit(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"in/games.sgf": "(;DT[2016-03-09]PB[Lee Sedol]PW[AlphaGo];B[pd])" +
+			"(;DT[2016-03-10]PB[AlphaGo]PW[Lee Sedol];B[dd])" +
+			"(;DT[2016-03-10]PB[AlphaGo]PW[Lee Sedol];B[cc])" +
+			"(;PB[a/b];B[ee])",
+	})
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out")
+	status, _, stderr := runCommand("", "split", "-o", out, "-template", "{DT}-{PB}-vs-{PW}.sgf", filepath.Join(dir, "in"))
+	if status != 0 {
+		t.Fatalf("split returned %d: %s", status, stderr)
+	}
+
+	files := map[string]string{
+		"2016-03-09-Lee Sedol-vs-AlphaGo.sgf":   "(;DT[2016-03-09]PB[Lee Sedol]PW[AlphaGo]\n ;B[pd])\n",
+		"2016-03-10-AlphaGo-vs-Lee Sedol.sgf":   "(;DT[2016-03-10]PB[AlphaGo]PW[Lee Sedol]\n ;B[dd])\n",
+		"2016-03-10-AlphaGo-vs-Lee Sedol-2.sgf": "(;DT[2016-03-10]PB[AlphaGo]PW[Lee Sedol]\n ;B[cc])\n",
+		"-a_b-vs-.sgf":                          "(;PB[a/b]\n ;B[ee])\n",
+	}
+
+	infos, err := ioutil.ReadDir(out)
+	if err != nil || len(infos) != len(files) {
+		t.Fatalf("split wrote wrong files: %v", infos)
+	}
+
+	for name, wanted := range files {
+		if data, err := ioutil.ReadFile(filepath.Join(out, name)); err != nil || string(data) != wanted {
+			t.Errorf("split file %s mismatch. Got: %q", name, data)
+		}
+	}
+
+	// Existing files are not overwritten
+	if status, _, _ := runCommand("(;B[aa])(;B[bb])", "split", "-o", out, "-template", "{n}.sgf"); status != 0 {
+		t.Fatalf("split returned %d", status)
+	}
+
+	if status, _, _ := runCommand("(;B[cc])", "split", "-o", out, "-template", "{name}-{n}.sgf"); status != 0 {
+		t.Fatalf("split returned %d", status)
+	}
+
+	if status, _, _ := runCommand("(;B[dd])", "split", "-o", out, "-template", "{n}.sgf"); status != 0 {
+		t.Fatalf("split returned %d", status)
+	}
+
+	names := []string{}
+	for _, name := range []string{"1.sgf", "2.sgf", "1-2.sgf", "stdin-1.sgf"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err == nil {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) != 4 {
+		t.Errorf("split did not write unique files. Got: %v", names)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"a.sgf":   "(;GN[a])(;GN[b])",
+		"b/c.sgf": "(;GN[c])",
+		"b/d.txt": "not a game",
+	})
+	defer os.RemoveAll(dir)
+
+	status, stdout, _ := runCommand("", "join", "-style", "compact", dir)
+	if status != 0 || stdout != "(;GN[a])(;GN[b])(;GN[c])\n" {
+		t.Errorf("join mismatch. Got: %d %q", status, stdout)
+	}
+
+	file := filepath.Join(dir, "joined.sgf")
+	if status, _, _ := runCommand("", "join", "-o", file, filepath.Join(dir, "b", "c.sgf"), filepath.Join(dir, "a.sgf")); status != 0 {
+		t.Fatalf("join returned %d", status)
+	}
+
+	collection, err := sgf.ParseSgfFile(file)
+	if err != nil {
+		t.Fatalf("join wrote invalid SGF: %s", err)
+	}
+
+	names := []string{}
+	for _, gameTree := range collection.GameTrees {
+		names = append(names, gameTree.Nodes[0].Property("GN").Values[0])
+	}
+
+	if !reflect.DeepEqual(names, []string{"c", "a", "b"}) {
+		t.Errorf("join order mismatch. Got: %v", names)
+	}
+
+	if status, _, _ := runCommand("", "join", filepath.Join(dir, "b", "d.txt")); status != 1 {
+		t.Errorf("join of an unknown format did not fail.")
+	}
+}
+
+func TestSplitJoinCharset(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"latin1.sgf": "(;CA[ISO-8859-1]PB[J\xe4rvi];B[aa])(;CA[ISO-8859-1]PB[\xc5se];B[bb])",
+	})
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "latin1.sgf"), filepath.Join(dir, "out")
+	if status, _, stderr := runCommand("", "split", "-o", out, "-style", "compact", "-template", "{PB}.sgf", in); status != 0 {
+		t.Fatalf("split returned %d: %s", status, stderr)
+	}
+
+	files := map[string]string{
+		"Järvi.sgf": "(;CA[UTF-8]PB[Järvi];B[aa])\n",
+		"Åse.sgf":   "(;CA[UTF-8]PB[Åse];B[bb])\n",
+	}
+
+	for name, wanted := range files {
+		if data, err := ioutil.ReadFile(filepath.Join(out, name)); err != nil || string(data) != wanted {
+			t.Errorf("split file %s mismatch. Got: %q", name, data)
+		}
+	}
+
+	wanted := "(;CA[UTF-8]PB[Järvi];B[aa])(;CA[UTF-8]PB[Åse];B[bb])\n"
+	if status, stdout, _ := runCommand("", "join", "-style", "compact", in); status != 0 || stdout != wanted {
+		t.Errorf("join mismatch. Got: %d %q", status, stdout)
+	}
+}
+
+func TestGameFileName(t *testing.T) {
+	collection, _ := sgf.ParseSgf("(;GM[1];PB[Black: \\]x];B[aa](;C[a])(;C[b]))")
+	gameTree := collection.GameTrees[0]
+
+	var tests = []struct {
+		template string
+		name     string
+	}{
+		{"{PB}.sgf", "Black_ ]x.sgf"},
+		{"{name}-{n}-{GM}{C}.sgf", "games-3-1a.sgf"},
+		{"{PW}{pb}.sgf", "{pb}.sgf"},
+	}
+
+	for _, test := range tests {
+		if name := gameFileName(test.template, "games", 3, gameTree); name != test.name {
+			t.Errorf("gameFileName(%s) mismatch. Wanted: %s, got: %s", test.template, test.name, name)
+		}
+	}
+
+	// Game info can't move the file outside the output directory
+	collection, _ = sgf.ParseSgf("(;PB[..]PW[.]EV[a/..];B[aa])")
+	gameTree = collection.GameTrees[0]
+
+	tests = []struct {
+		template string
+		name     string
+	}{
+		{"{PB}/{n}.sgf", "_/3.sgf"},
+		{"{PW}{PW}/{n}.sgf", "_/3.sgf"},
+		{"{PW}/{n}.sgf", "./3.sgf"},
+		{"{EV}/{n}.sgf", "a_../3.sgf"},
+	}
+
+	for _, test := range tests {
+		if name := gameFileName(test.template, "games", 3, gameTree); name != filepath.FromSlash(test.name) {
+			t.Errorf("gameFileName(%s) mismatch. Wanted: %s, got: %s", test.template, test.name, name)
+		}
+	}
+}
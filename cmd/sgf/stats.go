@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+// Statistics of a file or of all the files.
+type stats struct {
+	File       string         `json:"file,omitempty"`
+	Error      string         `json:"error,omitempty"` // file could not be read or parsed
+	Files      int            `json:"files,omitempty"` // number of files in the totals
+	Errors     int            `json:"errors,omitempty"`
+	Games      int            `json:"games"`
+	Nodes      int            `json:"nodes"`
+	Variations int            `json:"variations"` // GameTrees branching from another GameTree, main lines excluded
+	MaxDepth   int            `json:"maxDepth"`   // Nodes on the longest path from the root
+	Moves      int            `json:"moves"`      // moves on the main lines
+	Issues     int            `json:"issues"`     // problems found by Collection.Validate
+	Properties map[string]int `json:"properties"`
+	Unknown    map[string]int `json:"unknown"` // private and unknown properties
+	BoardSizes map[string]int `json:"boardSizes"`
+	Results    map[string]int `json:"results"`
+}
+
+func newStats(file string) *stats {
+	return &stats{
+		File:       file,
+		Properties: map[string]int{},
+		Unknown:    map[string]int{},
+		BoardSizes: map[string]int{},
+		Results:    map[string]int{},
+	}
+}
+
+func runStats(e *env, args []string) int {
+	flags := newFlagSet(e, "stats", "[flags] [path ...]")
+	jsonOutput := flags.Bool("json", false, "write the statistics as JSON")
+	totalOnly := flags.Bool("total", false, "report only the totals")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	files := []inputFile{{stdinName, stdinName}}
+	if flags.NArg() > 0 {
+		var err error
+		if files, err = findFiles(flags.Args(), inputExtensions(nil)...); err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+	}
+
+	fileStats := []*stats{}
+	total := newStats("")
+
+	for _, file := range files {
+		s := collectStats(e, file.path)
+		fileStats = append(fileStats, s)
+		total.add(s)
+	}
+
+	if *totalOnly {
+		fileStats = nil
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(struct {
+			Files []*stats `json:"files,omitempty"`
+			Total *stats   `json:"total"`
+		}{fileStats, total}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+
+		fmt.Fprintf(e.stdout, "%s\n", data)
+	} else {
+		writeStats(e.stdout, fileStats, total)
+	}
+
+	if total.Errors > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// Reads the file and returns its statistics.
+func collectStats(e *env, name string) *stats {
+	s := newStats(name)
+	s.Files = 1
+
+	data, err := readInput(e, name)
+	if err == nil {
+		var collection *sgf.Collection
+		if collection, err = readCollection(name, data, convertOptions{}); err == nil {
+			s.addCollection(collection)
+			return s
+		}
+	}
+
+	s.Errors = 1
+	s.Error = errorMessage(name, err)
+
+	return s
+}
+
+func (s *stats) addCollection(collection *sgf.Collection) {
+	s.Games = len(collection.GameTrees)
+	s.Issues = len(collection.Validate())
+
+	for _, gameTree := range collection.GameTrees {
+		if depth := s.addGameTree(gameTree); depth > s.MaxDepth {
+			s.MaxDepth = depth
+		}
+
+		root := gameTree.Nodes[0]
+
+		width, height, err := root.BoardSize()
+		switch {
+		case err != nil:
+			s.BoardSizes["invalid"]++
+		case width == height:
+			s.BoardSizes[fmt.Sprint(width)]++
+		default:
+			s.BoardSizes[fmt.Sprintf("%dx%d", width, height)]++
+		}
+
+		result := ""
+		if property := root.Property("RE"); property != nil && len(property.Values) > 0 {
+			result = property.Values[0]
+		}
+		s.Results[resultClass(result)]++
+
+		for _, node := range gameTree.MainLine() {
+			if node.Property("B") != nil || node.Property("W") != nil {
+				s.Moves++
+			}
+		}
+	}
+}
+
+// Counts the Nodes, properties and variations of the GameTree and returns its depth.
+func (s *stats) addGameTree(gameTree *sgf.GameTree) int {
+	s.Nodes += len(gameTree.Nodes)
+
+	for _, node := range gameTree.Nodes {
+		for _, property := range node.Properties {
+			s.Properties[property.Ident]++
+
+			if _, ok := sgf.LookupProperty(property.Ident); !ok {
+				s.Unknown[property.Ident]++
+			}
+		}
+	}
+
+	if len(gameTree.GameTrees) > 1 {
+		s.Variations += len(gameTree.GameTrees) - 1
+	}
+
+	depth := 0
+	for _, child := range gameTree.GameTrees {
+		if d := s.addGameTree(child); d > depth {
+			depth = d
+		}
+	}
+
+	return len(gameTree.Nodes) + depth
+}
+
+// Returns the class of the result (RE): "B+R", "B+T", "B+F" or "B+" for wins by points or unknown margin and the
+// same for White, "Draw", "Void", "?" for unknown results and "none" if there is no result.
+func resultClass(result string) string {
+	result = strings.TrimSpace(result)
+
+	switch {
+	case result == "":
+		return "none"
+	case result == "0" || strings.EqualFold(result, "Draw"):
+		return "Draw"
+	case strings.EqualFold(result, "Void"):
+		return "Void"
+	case len(result) >= 2 && (result[0] == 'B' || result[0] == 'W') && result[1] == '+':
+		switch reason := strings.ToUpper(result[2:]); reason {
+		case "R", "RESIGN", "T", "TIME", "F", "FORFEIT":
+			return result[:2] + reason[:1]
+		}
+
+		return result[:2]
+	}
+
+	return "?"
+}
+
+// Adds the statistics of a file to the totals.
+func (s *stats) add(other *stats) {
+	s.Files += other.Files
+	s.Errors += other.Errors
+	s.Games += other.Games
+	s.Nodes += other.Nodes
+	s.Variations += other.Variations
+	s.Moves += other.Moves
+	s.Issues += other.Issues
+
+	if other.MaxDepth > s.MaxDepth {
+		s.MaxDepth = other.MaxDepth
+	}
+
+	for _, counts := range [][2]map[string]int{
+		{s.Properties, other.Properties},
+		{s.Unknown, other.Unknown},
+		{s.BoardSizes, other.BoardSizes},
+		{s.Results, other.Results},
+	} {
+		for key, count := range counts[1] {
+			counts[0][key] += count
+		}
+	}
+}
+
+func (s *stats) summary() string {
+	return fmt.Sprintf("%d games, %d nodes, %d variations, max depth %d, %d moves, %d issues",
+		s.Games, s.Nodes, s.Variations, s.MaxDepth, s.Moves, s.Issues)
+}
+
+func writeStats(w io.Writer, fileStats []*stats, total *stats) {
+	for _, s := range fileStats {
+		if s.Error != "" {
+			fmt.Fprintf(w, "%s\n", s.Error)
+		} else {
+			fmt.Fprintf(w, "%s: %s\n", s.File, s.summary())
+		}
+	}
+
+	fmt.Fprintf(w, "total: %d files, %d errors, %s\n", total.Files, total.Errors, total.summary())
+
+	for _, section := range []struct {
+		title  string
+		counts map[string]int
+	}{
+		{"properties", total.Properties},
+		{"unknown properties", total.Unknown},
+		{"board sizes", total.BoardSizes},
+		{"results", total.Results},
+	} {
+		if len(section.counts) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s:\n", section.title)
+		for _, key := range sortedKeys(section.counts) {
+			fmt.Fprintf(w, "  %-8s %d\n", key, section.counts[key])
+		}
+	}
+}
+
+// Returns the keys by descending count, keys with equal counts in alphabetical order.
+func sortedKeys(counts map[string]int) []string {
+	keys := []string{}
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	data := "(;FF[4]SZ[19]RE[B+R]XX[1];B[aa](;W[bb];B[cc](;W[dd])(;W[ee]))(;W[ff]))" +
+		"(;SZ[9:7]RE[W+3.5];B[aa];W[bb];B[zz])"
+
+	status, stdout, _ := runCommand(data, "stats")
+
+	wanted := "<standard input>: 2 games, 11 nodes, 2 variations, max depth 5, 7 moves, 1 issues\n" +
+		"total: 1 files, 0 errors, 2 games, 11 nodes, 2 variations, max depth 5, 7 moves, 1 issues\n" +
+		"properties:\n  W        5\n  B        4\n  RE       2\n  SZ       2\n  FF       1\n  XX       1\n" +
+		"unknown properties:\n  XX       1\n" +
+		"board sizes:\n  19       1\n  9x7      1\n" +
+		"results:\n  B+R      1\n  W+       1\n"
+
+	if status != 0 || stdout != wanted {
+		t.Errorf("stats mismatch. Got: %d %q", status, stdout)
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"a.sgf":     "(;RE[0];B[aa])",
+		"b/c.sgf":   "(;RE[Void])(;RE[?])",
+		"b/d.sgf":   "(;B[aa]",
+		"b/e.ngf":   "not a game",
+		"b/f.other": "(;)",
+	})
+	defer os.RemoveAll(dir)
+
+	status, stdout, _ := runCommand("", "stats", "-json", "-total", dir)
+	if status != 1 {
+		t.Errorf("stats did not fail with invalid files.")
+	}
+
+	var result struct {
+		Files []*stats
+		Total *stats
+	}
+
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("stats -json returned invalid JSON: %s", err)
+	}
+
+	if len(result.Files) != 0 {
+		t.Errorf("stats -total returned the files.")
+	}
+
+	total := result.Total
+	if total.Files != 4 || total.Errors != 2 || total.Games != 3 || total.Moves != 1 {
+		t.Errorf("stats -json totals mismatch. Got: %+v", total)
+	}
+
+	if results := map[string]int{"Draw": 1, "Void": 1, "?": 1}; !reflect.DeepEqual(total.Results, results) {
+		t.Errorf("stats -json results mismatch. Got: %v", total.Results)
+	}
+
+	_, stdout, _ = runCommand("", "stats", "-json", filepath.Join(dir, "a.sgf"))
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil || len(result.Files) != 1 || result.Files[0].Nodes != 2 {
+		t.Errorf("stats -json of a file mismatch. Got: %s", stdout)
+	}
+}
+
+func TestResultClass(t *testing.T) {
+	var tests = []struct {
+		result string
+		class  string
+	}{
+		{"B+R", "B+R"},
+		{"W+Resign", "W+R"},
+		{"B+T", "B+T"},
+		{"W+F", "W+F"},
+		{"B+0.5", "B+"},
+		{"W+", "W+"},
+		{"Draw", "Draw"},
+		{"0", "Draw"},
+		{"Void", "Void"},
+		{"?", "?"},
+		{"Jigo", "?"},
+		{"", "none"},
+	}
+
+	for _, test := range tests {
+		if class := resultClass(test.result); class != test.class {
+			t.Errorf("resultClass(%s) mismatch. Wanted: %s, got: %s", test.result, test.class, class)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/toikarin/sgf"
+)
+
+func runValidate(e *env, args []string) int {
+	flags := newFlagSet(e, "validate", "[flags] [path ...]")
+	unknown := flags.Bool("unknown", false, "also report private and unknown properties")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	names := []string{stdinName}
+	if flags.NArg() > 0 {
+		files, err := sgfFiles(flags.Args())
+		if err != nil {
+			fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+			return 1
+		}
+
+		names = files
+	}
+
+	status := 0
+
+	for _, name := range names {
+		if !validateFile(e, name, *unknown) {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+// Validates the file and reports the problems. Returns false if any were found.
+func validateFile(e *env, name string, unknown bool) bool {
+	data, err := readInput(e, name)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "sgf: %s\n", err)
+		return false
+	}
+
+	text, _, err := decodeSgf(data)
+	if err != nil {
+		fmt.Fprintln(e.stdout, errorMessage(name, err))
+		return false
+	}
+
+	collection, layout, err := sgf.ParseSgfWithLayout(text)
+	if err != nil {
+		fmt.Fprintln(e.stdout, errorMessage(name, err))
+		return false
+	}
+
+	issues := collection.Validate()
+
+	if unknown {
+		issues = append(issues, unknownProperties(collection)...)
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(e.stdout, issueMessage(name, layout, issue))
+	}
+
+	return len(issues) == 0
+}
+
+// Returns the message of an issue prefixed with the position of its Property or Node.
+func issueMessage(name string, layout *sgf.Layout, issue sgf.Issue) string {
+	line, column, ok := 0, 0, false
+
+	switch {
+	case issue.Property != nil:
+		line, column, ok = layout.PropertyPosition(issue.Property)
+	case issue.Node != nil:
+		line, column, ok = layout.NodePosition(issue.Node)
+	}
+
+	if !ok {
+		return fmt.Sprintf("%s: %s", name, issue)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", name, line, column, issue)
+}
+
+// Returns an issue of every property missing from the property registry.
+func unknownProperties(collection *sgf.Collection) []sgf.Issue {
+	issues := []sgf.Issue{}
+
+	var walk func(gameTree *sgf.GameTree)
+	walk = func(gameTree *sgf.GameTree) {
+		for _, node := range gameTree.Nodes {
+			for _, property := range node.Properties {
+				if _, ok := sgf.LookupProperty(property.Ident); !ok {
+					issues = append(issues, sgf.Issue{Node: node, Property: property, Message: "Unknown property"})
+				}
+			}
+		}
+
+		for _, child := range gameTree.GameTrees {
+			walk(child)
+		}
+	}
+
+	for _, gameTree := range collection.GameTrees {
+		walk(gameTree)
+	}
+
+	return issues
+}
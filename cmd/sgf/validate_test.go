@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Runs the command with the input and returns the exit status, standard output and standard error.
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := run(&env{strings.NewReader(stdin), &stdout, &stderr}, args)
+
+	return status, stdout.String(), stderr.String()
+}
+
+// Creates a temporary directory with the files and returns its path.
+func tempDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "sgf")
+	if err != nil {
+		t.Fatalf("TempDir returned error: %s", err)
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll returned error: %s", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile returned error: %s", err)
+		}
+	}
+
+	return dir
+}
+
+func TestValidate(t *testing.T) {
+	var tests = []struct {
+		stdin  string
+		args   []string
+		status int
+		output string
+	}{
+		{"(;FF[4]SZ[9];B[cc];W[dd])", []string{"validate"}, 0, ""},
+		{"(;FF[4]\n;B[aa]\n  ;w[bb])", []string{"validate"}, 1, "<standard input>:3:4: Invalid character w\n"},
+		{"(;FF[4]SZ[9]\n;B[jj]\r\n;W[aa]GM[1])", []string{"validate"}, 1,
+			"<standard input>:2:2: B: Point jj is not on the board\n" +
+				"<standard input>:3:7: GM: Root property outside of the root Node\n"},
+		{"(;SZ[9]XY[1];B[aa]CR[bb]TR[bb])", []string{"validate"}, 1,
+			"<standard input>:1:13: Point bb has both CR and TR\n"},
+		{"(;SZ[9]XY[1])", []string{"validate", "-unknown"}, 1, "<standard input>:1:8: XY: Unknown property\n"},
+		{"", []string{"validate"}, 1, "<standard input>: Collection does not have any GameTrees\n"},
+		{"(;CA[latin1]SZ[9]PB[J\xf6rg];B[jj])", []string{"validate"}, 1,
+			"<standard input>:1:27: B: Point jj is not on the board\n"},
+		{"(;CA[Shift_JIS])", []string{"validate"}, 1, "<standard input>: unsupported charset \"Shift_JIS\"\n"},
+	}
+
+	for _, test := range tests {
+		status, stdout, _ := runCommand(test.stdin, test.args...)
+
+		if status != test.status || stdout != test.output {
+			t.Errorf("%v with %q mismatch. Wanted: %d %q, got: %d %q", test.args, test.stdin, test.status, test.output, status, stdout)
+		}
+	}
+}
+
+func TestValidateDirectory(t *testing.T) {
+	dir := tempDir(t, map[string]string{
+		"a.sgf":       "(;FF[4];B[aa])",
+		"b/c.SGF":     "(;FF[4];B[aa]",
+		"b/notes.txt": "not sgf",
+	})
+	defer os.RemoveAll(dir)
+
+	status, stdout, _ := runCommand("", "validate", dir)
+
+	wanted := filepath.Join(dir, "b", "c.SGF") + ":1:10: Game tree did not close properly.\n"
+	if status != 1 || stdout != wanted {
+		t.Errorf("validate %s mismatch. Got: %d %q", dir, status, stdout)
+	}
+
+	if status, _, stderr := runCommand("", "validate", filepath.Join(dir, "missing.sgf")); status != 1 || stderr == "" {
+		t.Errorf("validate did not report a missing file.")
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	if status, _, stderr := runCommand("", "foo"); status != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Errorf("Unknown command mismatch. Got: %d %q", status, stderr)
+	}
+}
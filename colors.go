@@ -0,0 +1,92 @@
+package sgf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Properties exchanged by SwapColors.
+var swappedIdents = map[string]string{
+	"B": "W", "AB": "AW", "PB": "PW", "BR": "WR", "BT": "WT", "BL": "WL", "OB": "OW", "GB": "GW", "TB": "TW",
+	"W": "B", "AW": "AB", "PW": "PB", "WR": "BR", "WT": "BT", "WL": "BL", "OW": "OB", "GW": "GB", "TW": "TB",
+}
+
+// Swaps the colors of the players in all the Nodes of the collection, so that Black plays the moves of White and
+// vice versa:
+//   - Properties of Black and White are exchanged: B/W, AB/AW, PB/PW, BR/WR, BT/WT, BL/WL, OB/OW, GB/GW and TB/TW.
+//   - Player to play (PL) is changed to the other color.
+//   - Winner of the result (RE) is changed, e.g. "B+R" to "W+R".
+//   - Komi (KM) and the value of the position (V) are negated.
+//
+// Properties are changed in place, keeping their order in the Nodes. Values that can't be swapped, such as a komi
+// that is not a number, are left unchanged.
+func SwapColors(collection *Collection) {
+	for _, gameTree := range collection.GameTrees {
+		swapGameTreeColors(gameTree)
+	}
+}
+
+func swapGameTreeColors(gameTree *GameTree) {
+	for _, node := range gameTree.Nodes {
+		for _, property := range node.Properties {
+			if ident, ok := swappedIdents[property.Ident]; ok {
+				property.Ident = ident
+				continue
+			}
+
+			for i, value := range property.Values {
+				switch property.Ident {
+				case "PL":
+					property.Values[i] = swapColorValue(value)
+				case "RE":
+					property.Values[i] = swapResult(value)
+				case "KM", "V":
+					property.Values[i] = negateReal(value)
+				}
+			}
+		}
+	}
+
+	for _, childGameTree := range gameTree.GameTrees {
+		swapGameTreeColors(childGameTree)
+	}
+}
+
+func swapColorValue(value string) string {
+	switch strings.TrimSpace(value) {
+	case "B":
+		return "W"
+	case "W":
+		return "B"
+	}
+
+	return value
+}
+
+// Returns the result with the winner changed. Draws, void and unknown results stay as they are.
+func swapResult(value string) string {
+	if len(value) > 1 && value[1] == '+' {
+		return swapColorValue(value[:1]) + value[1:]
+	}
+
+	return value
+}
+
+// Returns the real value negated, keeping its format, e.g. "6.50" is negated to "-6.50". Zero is left unchanged.
+func negateReal(value string) string {
+	trimmed := strings.TrimSpace(value)
+
+	f, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil || f == 0 {
+		return value
+	}
+
+	switch trimmed[0] {
+	case '-':
+		return trimmed[1:]
+	case '+':
+		return "-" + trimmed[1:]
+	}
+
+	return "-" + trimmed
+}
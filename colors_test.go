@@ -0,0 +1,39 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestSwapColors(t *testing.T) {
+	var tests = []struct {
+		sgf     string
+		swapped string
+	}{
+		{"(;PB[Lee]BR[9p]PW[AlphaGo]WR[-]BT[Korea]WT[DeepMind]KM[7.5]RE[W+R]HA[0];B[pd]BL[60]OB[3];W[dd]WL[30.5]OW[1])",
+			"(;PW[Lee]WR[9p]PB[AlphaGo]BR[-]WT[Korea]BT[DeepMind]KM[-7.5]RE[B+R]HA[0];W[pd]WL[60]OW[3];B[dd]BL[30.5]OB[1])"},
+		{"(;AB[aa][bb]AW[cc]PL[B]C[Black to play];W[dd]GB[1]V[-3.50](;B[ee]GW[2]V[+1])(;B[ff]TB[aa]TW[bb]))",
+			"(;AW[aa][bb]AB[cc]PL[W]C[Black to play];B[dd]GW[1]V[3.50](;W[ee]GB[2]V[-1])(;W[ff]TW[aa]TB[bb]))"},
+		{"(;KM[0]RE[0])(;KM[x]RE[Draw])(;RE[B+0.5]KM[-6.5])(;RE[?]PL[w])",
+			"(;KM[0]RE[0])(;KM[x]RE[Draw])(;RE[W+0.5]KM[6.5])(;RE[?]PL[w])"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.sgf)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.sgf, err)
+		}
+
+		SwapColors(collection)
+		if s := collection.Sgf(SgfFormat{}); s != test.swapped {
+			t.Errorf("SwapColors(%s) mismatch.\nWanted: %s\ngot:    %s", test.sgf, test.swapped, s)
+		}
+
+		// Swapping twice restores the original, apart from the format of the reals
+		original, _ := ParseSgf(test.sgf)
+
+		SwapColors(collection)
+		if collection.Hash() != original.Hash() {
+			t.Errorf("SwapColors(%s) twice mismatch. Got: %s", test.sgf, collection.Sgf(SgfFormat{}))
+		}
+	}
+}
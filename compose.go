@@ -0,0 +1,83 @@
+package sgf
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Compose is a composed property value containing two values separated by ':', e.g. LB[dd:Label] or SZ[19:13].
+// Colons and backslashes inside the two parts are escaped with '\'. Values of composed properties (see
+// PropertyInfo.Composed) are stored in this form by the parser.
+type Compose string
+
+var composeEscaper = strings.NewReplacer("\\", "\\\\", ":", "\\:")
+
+// Creates a new composed value of the given parts. Colons and backslashes in the parts are escaped.
+func NewCompose(first, second string) Compose {
+	return Compose(composeEscaper.Replace(first) + ":" + composeEscaper.Replace(second))
+}
+
+// Splits the composed value at the first unescaped ':' and removes escaping from the parts. The last return value is
+// false if the value is not composed, in which case the whole unescaped value is returned as the first part.
+func (compose Compose) Split() (string, string, bool) {
+	var first bytes.Buffer
+	escaped := false
+
+	for i, c := range compose {
+		switch {
+		case escaped:
+			first.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ':':
+			return first.String(), unescapeCompose(string(compose[i+1:])), true
+		default:
+			first.WriteRune(c)
+		}
+	}
+
+	return first.String(), "", false
+}
+
+// Removes the escaping of a single composed part.
+func unescapeCompose(part string) string {
+	var buffer bytes.Buffer
+	escaped := false
+
+	for _, c := range part {
+		if c == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+
+		buffer.WriteRune(c)
+		escaped = false
+	}
+
+	return buffer.String()
+}
+
+// Encodes a value of a composed property for writing it to a SGF file. Both parts are encoded separately and colons
+// inside the parts are escaped.
+func encodeComposedValue(info PropertyInfo, value string) string {
+	first, second, ok := Compose(value).Split()
+
+	encoded := encodeComposedPart(info.Value, first)
+	if ok {
+		encoded += ":" + encodeComposedPart(info.Composed, second)
+	}
+
+	return encoded
+}
+
+func encodeComposedPart(valueType ValueType, part string) string {
+	switch valueType {
+	case ValueText:
+		part = normalizeLinebreaks(part)
+	case ValueSimpleText:
+		part = normalizeSimpleText(part)
+	}
+
+	return strings.Replace(escapeValue(part), ":", "\\:", -1)
+}
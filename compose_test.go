@@ -0,0 +1,78 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestComposeSplit(t *testing.T) {
+	var tests = []struct {
+		compose Compose
+		first   string
+		second  string
+		ok      bool
+	}{
+		{"", "", "", false},
+		{"19", "19", "", false},
+		{"19:13", "19", "13", true},
+		{"dd:", "dd", "", true},
+		{"dd:a:b", "dd", "a:b", true},
+		{"dd:a\\:b", "dd", "a:b", true},
+		{"a\\:b:c\\\\", "a:b", "c\\", true},
+		{"a\\\\:b", "a\\", "b", true},
+	}
+
+	for _, test := range tests {
+		first, second, ok := test.compose.Split()
+
+		if first != test.first || second != test.second || ok != test.ok {
+			t.Errorf("Compose(%q).Split() mismatch. wanted: %q, %q, %t, got: %q, %q, %t.", test.compose, test.first, test.second, test.ok, first, second, ok)
+		}
+	}
+}
+
+func TestNewCompose(t *testing.T) {
+	compose := NewCompose("a:b\\", "c:d")
+
+	if compose != "a\\:b\\\\:c\\:d" {
+		t.Errorf("NewCompose() mismatch. Got: %q.", compose)
+	}
+
+	if first, second, ok := compose.Split(); first != "a:b\\" || second != "c:d" || !ok {
+		t.Errorf("NewCompose().Split() did not return the original parts. Got: %q, %q.", first, second)
+	}
+}
+
+func TestComposedRoundTrip(t *testing.T) {
+	var tests = []struct {
+		data   string
+		wanted string
+	}{
+		{"(;AP[CGoban\\:3:1.0]SZ[19:13]LB[dd:12\\:30][ee:a\\\\])", ""},
+		{"(;FG[]FG[1:Figure\\: 1])", ""},
+		// Composed values are escaped when written, other values are not
+		{"(;LB[dd:a:b]C[a\\:b])", "(;LB[dd:a\\:b]C[a:b])"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Errorf("ParseSgf(%s) returned error.", test.data)
+			continue
+		}
+
+		wanted := test.wanted
+		if wanted == "" {
+			wanted = test.data
+		}
+
+		if sgf := collection.Sgf(NoNewLinesSgfFormat); sgf != wanted {
+			t.Errorf("collection.Sgf(%s) mismatch. Got: %s.", test.data, sgf)
+		}
+	}
+
+	collection, _ := ParseSgf("(;LB[dd:12\\:30])")
+	labels, err := collection.GameTrees[0].Nodes[0].Labels()
+	if err != nil || len(labels) != 1 || labels[0].Text != "12:30" {
+		t.Errorf("Labels() mismatch. Got: %v.", labels)
+	}
+}
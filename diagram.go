@@ -0,0 +1,213 @@
+package sgf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Character set of the board diagrams.
+type DiagramStyle int
+
+const (
+	DiagramASCII   DiagramStyle = iota // X and O stones on a grid of dots
+	DiagramUnicode                     // ● and ○ stones on a box drawing grid
+)
+
+// Options for board diagrams.
+type DiagramOptions struct {
+	Style       DiagramStyle
+	Coordinates bool // draw column letters and row numbers
+	LastMove    bool // surround the last move with parentheses
+	Crop        bool // draw only the visible area (VW) in effect
+}
+
+var (
+	// Default diagram options.
+	DefaultDiagramOptions = DiagramOptions{Style: DiagramASCII, Coordinates: true, LastMove: true}
+)
+
+// Characters of the points, indexed by Color and Shape. Empty points without a shape are drawn as grid.
+var diagramSymbols = map[DiagramStyle][3][6]string{
+	DiagramASCII: {
+		{"", "C", "S", "T", "M", ""},
+		{"X", "B", "#", "Y", "Z", "X"},
+		{"O", "W", "@", "Q", "P", "O"},
+	},
+	DiagramUnicode: {
+		{"", "◌", "⬚", "▵", "×", ""},
+		{"●", "◉", "■", "▲", "✖", "●"},
+		{"○", "◎", "□", "△", "✕", "○"},
+	},
+}
+
+// Column letters of the coordinates. I is skipped as usual in Go diagrams.
+const columnLetters = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+
+// Draws the board position after the last Node of the given path (see GameTree.PathTo) as text. Shapes (CR, SQ, TR,
+// MA) are drawn with symbols and labels (LB) with the first character of the label:
+//
+//	  A B C D E F G
+//	7 . . . . . . .
+//	6 . . . . . . .
+//	5 . . X O + . .
+//	4 . . .(X). . .
+//	3 . . T . a . .
+//	2 . . . . . . .
+//	1 . . . . . . .
+//
+// In the ASCII style marked stones use the usual letters: B, #, Y and Z are black stones with a circle, square,
+// triangle and cross, W, @, Q and P white ones.
+func Diagram(path []*Node, options DiagramOptions) (string, error) {
+	board, err := Position(path)
+	if err != nil {
+		return "", err
+	}
+
+	node := path[len(path)-1]
+
+	shapes, err := node.Shapes()
+	if err != nil {
+		return "", err
+	}
+
+	labels := map[Point]string{}
+	nodeLabels, err := node.Labels()
+	if err != nil {
+		return "", err
+	}
+
+	for _, label := range nodeLabels {
+		if r, _ := utf8.DecodeRuneInString(label.Text); r != utf8.RuneError {
+			labels[label.Point] = string(r)
+		}
+	}
+
+	lastMove := Point{-1, -1}
+	if options.LastMove {
+		color, point, pass, err := node.Move(board.Width, board.Height)
+		if err != nil {
+			return "", err
+		}
+
+		if color != Empty && !pass {
+			lastMove = point
+		}
+	}
+
+	x1, y1, x2, y2 := 0, 0, board.Width-1, board.Height-1
+	if options.Crop {
+		topLeft, bottomRight, err := ViewRectangle(path, board.Width, board.Height)
+		if err != nil {
+			return "", err
+		}
+
+		x1, y1, x2, y2 = topLeft.X, topLeft.Y, bottomRight.X, bottomRight.Y
+	}
+
+	stars := map[Point]bool{}
+	for _, point := range StarPoints(board.Width, board.Height) {
+		stars[point] = true
+	}
+
+	symbols := diagramSymbols[options.Style]
+	rowLabelWidth := len(fmt.Sprint(board.Height))
+
+	var buffer bytes.Buffer
+
+	if options.Coordinates {
+		buffer.WriteString(strings.Repeat(" ", rowLabelWidth))
+		for x := x1; x <= x2; x++ {
+			buffer.WriteString(" " + ColumnLabel(x, board.Width))
+		}
+		buffer.WriteString("\n")
+	}
+
+	for y := y1; y <= y2; y++ {
+		var line bytes.Buffer
+
+		if options.Coordinates {
+			line.WriteString(fmt.Sprintf("%*d", rowLabelWidth, board.Height-y))
+		}
+
+		for x := x1; x <= x2; x++ {
+			point := Point{x, y}
+
+			// Separator before the point
+			switch {
+			case point == lastMove:
+				line.WriteString("(")
+			case x > x1 && (Point{x - 1, y}) == lastMove:
+				line.WriteString(")")
+			case x > x1 && options.Style == DiagramUnicode:
+				line.WriteString("─")
+			default:
+				line.WriteString(" ")
+			}
+
+			switch symbol := symbols[board.At(point)][shapes[point]]; {
+			case labels[point] != "":
+				line.WriteString(labels[point])
+			case symbol != "":
+				line.WriteString(symbol)
+			default:
+				line.WriteString(gridSymbol(point, board, stars[point], options.Style))
+			}
+		}
+
+		if (Point{x2, y}) == lastMove {
+			line.WriteString(")")
+		}
+
+		buffer.WriteString(strings.TrimRight(line.String(), " "))
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String(), nil
+}
+
+// Returns the character of an empty point.
+func gridSymbol(point Point, board *Board, star bool, style DiagramStyle) string {
+	if style == DiagramASCII {
+		if star {
+			return "+"
+		}
+
+		return "."
+	}
+
+	if star {
+		return "╋"
+	}
+
+	// Box drawing characters indexed by the vertical and the horizontal position: top/left edge, middle, bottom/right
+	// edge
+	grid := [3][3]string{
+		{"┌", "┬", "┐"},
+		{"├", "┼", "┤"},
+		{"└", "┴", "┘"},
+	}
+
+	return grid[edgeIndex(point.Y, board.Height)][edgeIndex(point.X, board.Width)]
+}
+
+func edgeIndex(c, size int) int {
+	switch c {
+	case 0:
+		return 0
+	case size - 1:
+		return 2
+	}
+
+	return 1
+}
+
+// Returns the label of the column x in diagram coordinates. Boards wider than 25 points use SGF coordinates.
+func ColumnLabel(x, width int) string {
+	if width > len(columnLetters) {
+		return string(coordinateByte(x))
+	}
+
+	return columnLetters[x : x+1]
+}
@@ -0,0 +1,96 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestDiagram(t *testing.T) {
+	var tests = []struct {
+		data    string
+		options DiagramOptions
+		wanted  string
+	}{
+		{"(;SZ[7]AB[cc]AW[dc];B[dd]TR[ce]LB[ee:abc])", DefaultDiagramOptions,
+			"  A B C D E F G\n" +
+				"7 . . . . . . .\n" +
+				"6 . . . . . . .\n" +
+				"5 . . X O + . .\n" +
+				"4 . . .(X). . .\n" +
+				"3 . . T . a . .\n" +
+				"2 . . . . . . .\n" +
+				"1 . . . . . . .\n"},
+		{"(;SZ[7]AB[cc]AW[dc];B[dd]TR[ce]LB[ee:abc])", DiagramOptions{Style: DiagramUnicode, LastMove: true},
+			" ┌─┬─┬─┬─┬─┬─┐\n" +
+				" ├─┼─┼─┼─┼─┼─┤\n" +
+				" ├─┼─●─○─╋─┼─┤\n" +
+				" ├─┼─┼(●)┼─┼─┤\n" +
+				" ├─┼─▵─┼─a─┼─┤\n" +
+				" ├─┼─┼─┼─┼─┼─┤\n" +
+				" └─┴─┴─┴─┴─┴─┘\n"},
+		{"(;SZ[5]AB[aa][ba]AW[ab][bb]CR[aa]SQ[ab]MA[cc])", DiagramOptions{},
+			" B X . . .\n" +
+				" @ O . . .\n" +
+				" . . M . .\n" +
+				" . . . . .\n" +
+				" . . . . .\n"},
+		{"(;SZ[9];B[aa];W[ba];B[bb];W[ab]CR[bb]VW[aa:ee])", DiagramOptions{Coordinates: true, LastMove: true, Crop: true},
+			"  A B C D E\n" +
+				"9 . O . . .\n" +
+				"8(O)B . . .\n" +
+				"7 . . + . .\n" +
+				"6 . . . . .\n" +
+				"5 . . . . +\n"},
+		{"(;SZ[12];B[la])", DefaultDiagramOptions,
+			"   A B C D E F G H J K L M\n" +
+				"12 . . . . . . . . . . .(X)\n" +
+				"11 . . . . . . . . . . . .\n" +
+				"10 . . + . . . . . . + . .\n" +
+				" 9 . . . . . . . . . . . .\n" +
+				" 8 . . . . . . . . . . . .\n" +
+				" 7 . . . . . . . . . . . .\n" +
+				" 6 . . . . . . . . . . . .\n" +
+				" 5 . . . . . . . . . . . .\n" +
+				" 4 . . . . . . . . . . . .\n" +
+				" 3 . . + . . . . . . + . .\n" +
+				" 2 . . . . . . . . . . . .\n" +
+				" 1 . . . . . . . . . . . .\n"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.data, err)
+		}
+
+		diagram, err := Diagram(collection.GameTrees[0].MainLine(), test.options)
+		if err != nil {
+			t.Errorf("Diagram(%s) returned error: %s", test.data, err)
+			continue
+		}
+
+		if diagram != test.wanted {
+			t.Errorf("Diagram(%s) mismatch. Got:\n%s", test.data, diagram)
+		}
+	}
+}
+
+func TestDiagramErrors(t *testing.T) {
+	var errTests = []string{
+		"(;SZ[foo])",
+		"(;SZ[5]CR[a])",
+		"(;SZ[5]LB[aa])",
+		"(;SZ[5];B[x])",
+		"(;SZ[5]VW[aa:gg])",
+	}
+
+	for _, test := range errTests {
+		collection, err := ParseSgf(test)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test, err)
+		}
+
+		if _, err := Diagram(collection.GameTrees[0].MainLine(), DiagramOptions{LastMove: true, Crop: true}); err == nil {
+			t.Errorf("Diagram(%s) did not return error.", test)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package sgf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChangeType is the kind of a Change found by Diff.
+type ChangeType int
+
+const (
+	GameAdded           ChangeType = iota // GameTree added after the GameTrees of the first collection
+	GameRemoved                           // GameTree missing from the end of the second collection
+	VariationAdded                        // Child Node added to a Node
+	VariationRemoved                      // Child Node removed from a Node
+	VariationsReordered                   // Child Nodes of a Node in a different order
+	PropertyAdded                         // Property added to a Node
+	PropertyRemoved                       // Property removed from a Node
+	PropertyChanged                       // Values of a Property changed
+)
+
+// Names of the change types, indexed by ChangeType.
+var changeTypeNames = []string{"game added", "game removed", "variation added", "variation removed",
+	"variations reordered", "property added", "property removed", "property changed"}
+
+func (changeType ChangeType) String() string {
+	return changeTypeNames[changeType]
+}
+
+// Change is a difference between two collections, see Diff.
+type Change struct {
+	Type ChangeType
+	Game int // index of the GameTree
+
+	// Path from the root Node of the GameTree in the first collection to the changed Node, or to the parent Node of
+	// the changed variations. Nil for added and removed GameTrees.
+	Path []*Node
+
+	// First Node of the added or removed variation, or the root Node of the added or removed GameTree. The Node is
+	// from the second collection for added and from the first collection for removed variations and GameTrees.
+	Node *Node
+
+	Ident string   // ident of the changed Property
+	Old   []string // decoded values of the Property in the first collection, or the moves of the variations in the old order
+	New   []string // decoded values of the Property in the second collection, or the moves of the variations in the new order
+}
+
+// Returns the change in a human-readable form, e.g. `game 1, node 3 W[dd]: C changed: "good" -> "bad"`.
+func (change Change) String() string {
+	location := nodeLocation(change.Game, change.Path)
+
+	switch change.Type {
+	case GameAdded, GameRemoved:
+		return fmt.Sprintf("%s: %s", location, strings.TrimPrefix(change.Type.String(), "game "))
+	case VariationAdded:
+		return fmt.Sprintf("%s: variation %s added", location, variationName(change.Node))
+	case VariationRemoved:
+		return fmt.Sprintf("%s: variation %s removed", location, variationName(change.Node))
+	case VariationsReordered:
+		return fmt.Sprintf("%s: variations reordered: %s -> %s", location, strings.Join(change.Old, " "),
+			strings.Join(change.New, " "))
+	case PropertyAdded:
+		return fmt.Sprintf("%s: %s added: %s", location, change.Ident, quoteValues(change.New))
+	case PropertyRemoved:
+		return fmt.Sprintf("%s: %s removed: %s", location, change.Ident, quoteValues(change.Old))
+	}
+
+	return fmt.Sprintf("%s: %s changed: %s -> %s", location, change.Ident, quoteValues(change.Old),
+		quoteValues(change.New))
+}
+
+// Returns the structural differences of the collections a and b, in the order of the Nodes of a.
+//
+// GameTrees are compared by their index. Nodes are compared as a tree, regardless of how they are divided into
+// GameTrees: the children of a Node are matched by their move (B or W) instead of their index, so reordering
+// variations doesn't show up as changed Nodes. Properties are compared in their canonical form (see Canonicalize),
+// so differences in escaping, point list compression or the order of properties are not reported.
+func Diff(a, b *Collection) []Change {
+	changes := []Change{}
+
+	for i := 0; i < len(a.GameTrees) || i < len(b.GameTrees); i++ {
+		switch {
+		case i >= len(a.GameTrees):
+			changes = append(changes, Change{Type: GameAdded, Game: i, Node: firstNode(b.GameTrees[i])})
+		case i >= len(b.GameTrees):
+			changes = append(changes, Change{Type: GameRemoved, Game: i, Node: firstNode(a.GameTrees[i])})
+		case len(a.GameTrees[i].Nodes) > 0 && len(b.GameTrees[i].Nodes) > 0:
+			changes = diffNodes(changes, i, nil, treeNode{a.GameTrees[i], 0}, treeNode{b.GameTrees[i], 0})
+		}
+	}
+
+	return changes
+}
+
+func diffNodes(changes []Change, game int, path []*Node, a, b treeNode) []Change {
+	path = append(path[:len(path):len(path)], a.node())
+
+	changes = diffProperties(changes, game, path, a.node(), b.node())
+
+	aChildren, bChildren := a.children(), b.children()
+	matched := matchNodes(aChildren, bChildren)
+
+	removed := make([]bool, len(aChildren))
+	for i := range removed {
+		removed[i] = true
+	}
+
+	for _, i := range matched {
+		if i >= 0 {
+			removed[i] = false
+		}
+	}
+
+	for i, child := range aChildren {
+		if removed[i] {
+			changes = append(changes, Change{Type: VariationRemoved, Game: game, Path: path, Node: child.node()})
+		}
+	}
+
+	for j, i := range matched {
+		if i < 0 {
+			changes = append(changes, Change{Type: VariationAdded, Game: game, Path: path, Node: bChildren[j].node()})
+		}
+	}
+
+	// Matched children in the order of a and b
+	oldOrder, newOrder, reordered := []string{}, []string{}, false
+	for i, child := range aChildren {
+		if !removed[i] {
+			oldOrder = append(oldOrder, variationName(child.node()))
+		}
+	}
+
+	previous := -1
+	for j, i := range matched {
+		if i >= 0 {
+			newOrder = append(newOrder, variationName(bChildren[j].node()))
+			reordered = reordered || i < previous
+			previous = i
+		}
+	}
+
+	if reordered {
+		changes = append(changes, Change{Type: VariationsReordered, Game: game, Path: path, Old: oldOrder, New: newOrder})
+	}
+
+	for i, child := range aChildren {
+		for j := range matched {
+			if matched[j] == i {
+				changes = diffNodes(changes, game, path, child, bChildren[j])
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffProperties(changes []Change, game int, path []*Node, a, b *Node) []Change {
+	a, b = canonicalNode(a), canonicalNode(b)
+
+	for _, property := range a.Properties {
+		other := b.Property(property.Ident)
+
+		switch {
+		case other == nil:
+			changes = append(changes, Change{Type: PropertyRemoved, Game: game, Path: path, Ident: property.Ident,
+				Old: property.Values})
+		case !equalValues(property.Values, other.Values):
+			changes = append(changes, Change{Type: PropertyChanged, Game: game, Path: path, Ident: property.Ident,
+				Old: property.Values, New: other.Values})
+		}
+	}
+
+	for _, property := range b.Properties {
+		if a.Property(property.Ident) == nil {
+			changes = append(changes, Change{Type: PropertyAdded, Game: game, Path: path, Ident: property.Ident,
+				New: property.Values})
+		}
+	}
+
+	return changes
+}
+
+// Returns the GameTree, the depth and the move of the last Node of the path, e.g. "game 1, node 3 W[dd]".
+func nodeLocation(game int, path []*Node) string {
+	location := fmt.Sprintf("game %d", game+1)
+	if len(path) > 0 {
+		location += fmt.Sprintf(", node %d", len(path)-1)
+
+		if move := nodeMove(path[len(path)-1]); move != "" {
+			location += " " + move
+		}
+	}
+
+	return location
+}
+
+// Node in a tree of Nodes, given by the GameTree and the index of the Node in it.
+type treeNode struct {
+	gameTree *GameTree
+	index    int
+}
+
+func (n treeNode) node() *Node {
+	return n.gameTree.Nodes[n.index]
+}
+
+// Returns the child Nodes: the next Node of the GameTree, or the first Nodes of the child GameTrees.
+func (n treeNode) children() []treeNode {
+	if n.index+1 < len(n.gameTree.Nodes) {
+		return []treeNode{{n.gameTree, n.index + 1}}
+	}
+
+	children := []treeNode{}
+	for _, gameTree := range n.gameTree.GameTrees {
+		if len(gameTree.Nodes) > 0 {
+			children = append(children, treeNode{gameTree, 0})
+		}
+	}
+
+	return children
+}
+
+// Matches the Nodes b with the Nodes a by their move. Returns the index of the matching Node in a for each Node in b,
+// or -1 if there is no match. Nodes with the same move are matched in order.
+func matchNodes(a, b []treeNode) []int {
+	used := make([]bool, len(a))
+	matched := make([]int, len(b))
+
+	for j, bNode := range b {
+		matched[j] = -1
+		move := nodeMove(bNode.node())
+
+		for i, aNode := range a {
+			if !used[i] && nodeMove(aNode.node()) == move {
+				used[i] = true
+				matched[j] = i
+				break
+			}
+		}
+	}
+
+	return matched
+}
+
+// Returns the move of the Node, e.g. "B[pd]", or an empty string if the Node doesn't have a move.
+func nodeMove(node *Node) string {
+	for _, property := range node.Properties {
+		if (property.Ident == "B" || property.Ident == "W") && len(property.Values) > 0 {
+			return fmt.Sprintf("%s[%s]", property.Ident, property.Values[0])
+		}
+	}
+
+	return ""
+}
+
+// Returns the name of the variation starting from the Node: its move, or "-" for Nodes without a move.
+func variationName(node *Node) string {
+	if move := nodeMove(node); move != "" {
+		return move
+	}
+
+	return "-"
+}
+
+func firstNode(gameTree *GameTree) *Node {
+	if len(gameTree.Nodes) == 0 {
+		return nil
+	}
+
+	return gameTree.Nodes[0]
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func quoteValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+
+	return strings.Join(quoted, " ")
+}
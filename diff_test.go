@@ -0,0 +1,70 @@
+package sgf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var tests = []struct {
+		a, b    string
+		changes []string
+	}{
+		{"(;PB[Lee]C[a\\]b];B[pd](;W[dd])(;W[dp]))", "(;C[a\\]b]PB[Lee]\n;B[pd]\n(;W[dd])\n(;W[dp]))", []string{}},
+		{"(;B[aa];W[bb])", "(;B[aa](;W[bb]))", []string{}},
+		{"(;AB[aa][ab][ac])", "(;AB[aa:ac])", []string{}},
+		{"(;PB[Lee];B[pd]C[good])", "(;PB[Lee Sedol]KM[6.5];B[pd])", []string{
+			`game 1, node 0: PB changed: "Lee" -> "Lee Sedol"`,
+			`game 1, node 0: KM added: "6.5"`,
+			`game 1, node 1 B[pd]: C removed: "good"`,
+		}},
+		{"(;GM[1];B[pd](;W[dd])(;W[dp];B[pp])(;W[cc]))", "(;GM[1];B[pd](;W[dp];B[qq])(;W[dd])(;W[qq];B[aa]))", []string{
+			"game 1, node 1 B[pd]: variation W[cc] removed",
+			"game 1, node 1 B[pd]: variation W[qq] added",
+			"game 1, node 1 B[pd]: variations reordered: W[dd] W[dp] -> W[dp] W[dd]",
+			"game 1, node 2 W[dp]: variation B[pp] removed",
+			"game 1, node 2 W[dp]: variation B[qq] added",
+		}},
+		{"(;GM[1];B[aa];C[x])", "(;GM[1];B[aa];C[y])", []string{`game 1, node 2: C changed: "x" -> "y"`}},
+		{"(;GM[1];B[aa])", "(;GM[1];B[aa];C[y])", []string{"game 1, node 1 B[aa]: variation - added"}},
+		{"(;GN[a])", "(;GN[a])(;GN[b])", []string{"game 2: added"}},
+		{"(;GN[a])(;GN[b])", "(;GN[a])", []string{"game 2: removed"}},
+	}
+
+	for _, test := range tests {
+		a, err := ParseSgf(test.a)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.a, err)
+		}
+
+		b, err := ParseSgf(test.b)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.b, err)
+		}
+
+		changes := []string{}
+		for _, change := range Diff(a, b) {
+			changes = append(changes, change.String())
+		}
+
+		if !reflect.DeepEqual(changes, test.changes) {
+			t.Errorf("Diff(%s, %s) mismatch.\nWanted: %q\ngot:    %q", test.a, test.b, test.changes, changes)
+		}
+	}
+}
+
+func TestDiffChange(t *testing.T) {
+	a, _ := ParseSgf("(;GM[1];B[aa];W[bb]LB[cc:x])")
+	b, _ := ParseSgf("(;GM[1];B[aa];W[bb]LB[cc:y][dd:z])")
+
+	changes := Diff(a, b)
+	if len(changes) != 1 {
+		t.Fatalf("Diff() returned %d changes", len(changes))
+	}
+
+	change := changes[0]
+	if change.Type != PropertyChanged || change.Ident != "LB" || change.Path[2] != a.GameTrees[0].Nodes[2] ||
+		!reflect.DeepEqual(change.Old, []string{"cc:x"}) || !reflect.DeepEqual(change.New, []string{"cc:y", "dd:z"}) {
+		t.Errorf("Diff() change mismatch. Got: %+v", change)
+	}
+}
@@ -43,9 +43,30 @@ Example collection creation:
 
 	// ...
 
+Reading composed values:
+	// Values of composed properties such as LB, AP and FG keep "\:" and "\\" escaped in Property.Values,
+	// Compose.Split unescapes the parts. LB[aa:a\:b] gives "aa" and "a:b".
+	point, text, ok := sgf.Compose(node.Property("LB").Values[0]).Split()
+
 Converting collection to SGF:
 	collection.Sgf(sgf.DefaultSgfFormat)
 
+	// Root properties in canonical order, ten moves per line, lines wrapped at 100 characters and CRLF line endings
+	format := sgf.CGobanSgfFormat
+	format.MaxLineWidth = 100
+	format.CRLF = true
+	collection.Sgf(format)
+
+Editing a file without reformatting it:
+	collection, layout, err := sgf.ParseSgfWithLayout(data)
+	if err != nil {
+		panic(err)
+	}
+
+	// Only the modified property is formatted, everything else is written as it was
+	collection.GameTrees[0].Nodes[0].NewProperty("GN", "Game name")
+	collection.SgfWithLayout(sgf.DefaultSgfFormat, layout)
+
 Collection manipulation:
 	gt1 := collection.NewGameTree()
 	gt2 := collection.NewGameTree()
@@ -62,5 +83,67 @@ Collection manipulation:
 
 	// Remove gt1 from the collection by index. Current collections: [gt3].
 	collection.RemoveGameTreeAt(1)
+
+Board markup:
+	// Mark a triangle on the last move and label two points
+	node.SetShape(sgf.Point{15, 3}, sgf.ShapeTriangle)
+	node.SetLabel(sgf.Point{16, 2}, "A")
+	node.SetLabel(sgf.Point{2, 15}, "B")
+
+	// Draw an arrow and dim the lower half of the board until cleared with DD[]
+	node.AddArrow(sgf.Point{16, 2}, sgf.Point{2, 15})
+	node.SetDimmed(points...)
+
+	// Check that each point has at most one shape
+	err := node.ValidateMarkup()
+
+Board diagrams:
+	// Position after the last node of the main line and its text diagram
+	path := gameTree.MainLine()
+	board, err := sgf.Position(path)
+	diagram, err := sgf.Diagram(path, sgf.DefaultDiagramOptions)
+
+	// Unicode diagram of the visible area (VW) of a node
+	diagram, err = sgf.Diagram(gameTree.PathTo(node), sgf.DiagramOptions{Style: sgf.DiagramUnicode, Crop: true})
+
+Validation:
+	collection, layout, err := sgf.ParseSgfWithLayout(data)
+	if syntaxError, ok := err.(*sgf.SyntaxError); ok {
+		fmt.Printf("%d:%d: %s\n", syntaxError.Line, syntaxError.Column, syntaxError.Message)
+	}
+
+	// Report the problems with the position of the property
+	for _, issue := range collection.Validate() {
+		line, column, _ := layout.PropertyPosition(issue.Property)
+		fmt.Printf("%d:%d: %s\n", line, column, issue)
+	}
+
+Comparing collections:
+	// Variations are matched by their move. Prints e.g. game 1, node 3 W[dd]: C changed: "good" -> "bad"
+	for _, change := range sgf.Diff(old, new) {
+		fmt.Println(change)
+	}
+
+Merging edited copies:
+	// Conflicts are reported, e.g. game 1, node 3 W[dd]: C: base "a", ours "b", theirs "c"
+	options := sgf.DefaultMergeOptions
+	options.ConcatenateComments = true
+	merged, conflicts := sgf.Merge3(base, ours, theirs, options)
+
+Opening trees:
+	// Merge the first 20 moves of the games, counting the games of each Node in a GAMES property
+	options := sgf.DefaultMergeGamesOptions
+	options.Moves, options.Symmetry = 20, true
+	tree, err := sgf.MergeGames(collection.GameTrees, options)
+
+Board symmetries:
+	// Rotate and mirror the game so that its opening is played in the upper right corner
+	err := sgf.Transform(collection, sgf.CanonicalSymmetry(collection.GameTrees[0]))
+
+	// Study the game from the other side: B and W, players, komi and the result are swapped
+	sgf.SwapColors(collection)
+
+The sgf command in cmd/sgf works with files from the command line: validate, fmt, convert, stats, split, join, query,
+diff and merge. Run "sgf help" for the details.
 */
 package sgf
@@ -0,0 +1,118 @@
+package sgf
+
+import (
+	"strconv"
+)
+
+// Figure flags of the FG property.
+const (
+	FigureCoordinates   = 0x0001 // show coordinates
+	FigureName          = 0x0002 // show the name of the figure
+	FigureHideMoves     = 0x0004 // do not list moves that are not shown
+	FigureRemoveCapture = 0x0100 // remove captured stones
+	FigureHoshi         = 0x0200 // show hoshi dots
+	FigureIgnoreFlags   = 0x8000 // ignore the other flags and use the application defaults
+)
+
+// Returns the index of the Node starting the figure in effect at the last Node of the path: the last Node with FG
+// property. Returns 0 if none of the Nodes has FG.
+func FigureStart(path []*Node) int {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].Property("FG") != nil {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// Returns the flags and the name of the figure in effect at the last Node of the path. The last return value is false
+// if the figure does not set the flags (FG[], FigureIgnoreFlags or no FG at all), in which case the application
+// defaults should be used.
+func Figure(path []*Node) (int, string, bool) {
+	if len(path) == 0 {
+		return 0, "", false
+	}
+
+	property := path[FigureStart(path)].Property("FG")
+	if property == nil || len(property.Values) == 0 || property.Values[0] == "" {
+		return 0, "", false
+	}
+
+	first, name, _ := Compose(property.Values[0]).Split()
+
+	flags, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, "", false
+	}
+
+	if flags&FigureIgnoreFlags != 0 {
+		return 0, normalizeSimpleText(name), false
+	}
+
+	return flags, normalizeSimpleText(name), true
+}
+
+// Returns the numbers to print on the stones of the position after the last Node of the path. Moves are numbered from
+// the move number firstMove, or if it is 0, from the start of the current figure (see FigureStart). MN changes the
+// number of a move and PM the print mode: PM[0] hides the numbers and PM[2] prints the numbers modulo 100. If the
+// same point is played several times, the number of the last move is used as long as the stone is on the board.
+func MoveNumbers(path []*Node, firstMove int) (map[Point]int, error) {
+	board, err := Position(path)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if firstMove == 0 {
+		start = FigureStart(path)
+	}
+
+	numbers := map[Point]int{}
+	colors := map[Point]Color{}
+	number := 0
+	printMode := 1
+
+	for i, node := range path {
+		if property := node.Property("PM"); property != nil && len(property.Values) > 0 {
+			if mode, err := strconv.Atoi(property.Values[0]); err == nil {
+				printMode = mode
+			}
+		}
+
+		color, point, pass, err := node.Move(board.Width, board.Height)
+		if err != nil {
+			return nil, err
+		}
+
+		if color == Empty {
+			continue
+		}
+
+		number++
+		if property := node.Property("MN"); property != nil && len(property.Values) > 0 {
+			if n, err := strconv.Atoi(property.Values[0]); err == nil {
+				number = n
+			}
+		}
+
+		if pass || i < start || number < firstMove || printMode == 0 {
+			continue
+		}
+
+		numbers[point] = number
+		if printMode == 2 {
+			numbers[point] = (number-1)%100 + 1
+		}
+
+		colors[point] = color
+	}
+
+	for point, color := range colors {
+		if board.At(point) != color {
+			delete(numbers, point)
+		}
+	}
+
+	return numbers, nil
+}
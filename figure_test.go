@@ -0,0 +1,84 @@
+package sgf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFigure(t *testing.T) {
+	var tests = []struct {
+		data  string
+		start int
+		flags int
+		name  string
+		ok    bool
+	}{
+		{"(;;B[aa];W[bb])", 0, 0, "", false},
+		{"(;FG[257:Figure 1];B[aa];W[bb])", 0, 257, "Figure 1", true},
+		{"(;FG[257:Figure 1];B[aa]FG[];W[bb])", 1, 0, "", false},
+		{"(;;B[aa];W[bb]FG[513:Fig\\:2])", 2, 513, "Fig:2", true},
+		{"(;FG[33281:Figure 3];B[aa])", 0, 0, "Figure 3", false},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.data, err)
+		}
+
+		path := collection.GameTrees[0].MainLine()
+		if start := FigureStart(path); start != test.start {
+			t.Errorf("FigureStart(%s) mismatch. wanted: %d, got: %d.", test.data, test.start, start)
+		}
+
+		if flags, name, ok := Figure(path); flags != test.flags || name != test.name || ok != test.ok {
+			t.Errorf("Figure(%s) mismatch. Got: %d, %q, %t.", test.data, flags, name, ok)
+		}
+	}
+
+	if flags, name, ok := Figure(nil); flags != 0 || name != "" || ok {
+		t.Errorf("Figure() of an empty path mismatch. Got: %d, %q, %t.", flags, name, ok)
+	}
+}
+
+func TestMoveNumbers(t *testing.T) {
+	var tests = []struct {
+		data      string
+		firstMove int
+		wanted    string
+	}{
+		{"(;B[aa];W[bb];B[cc])", 0, "map[aa:1 bb:2 cc:3]"},
+		{"(;B[aa];W[bb]FG[];B[cc];W[])", 0, "map[bb:2 cc:3]"},
+		{"(;B[aa];W[bb];B[cc])", 2, "map[bb:2 cc:3]"},
+		{"(;B[aa];W[bb]MN[100];B[cc])", 0, "map[aa:1 bb:100 cc:101]"},
+		{"(;PM[2]B[aa]MN[100];W[bb];B[cc]PM[0];W[dd])", 0, "map[aa:100 bb:1]"},
+		{"(;SZ[5];B[ba];W[aa];B[ab];W[cc])", 0, "map[ab:3 ba:1 cc:4]"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.data, err)
+		}
+
+		numbers, err := MoveNumbers(collection.GameTrees[0].MainLine(), test.firstMove)
+		if err != nil {
+			t.Errorf("MoveNumbers(%s) returned error: %s", test.data, err)
+			continue
+		}
+
+		values := map[string]int{}
+		for point, number := range numbers {
+			values[point.String()] = number
+		}
+
+		if fmt.Sprint(values) != test.wanted {
+			t.Errorf("MoveNumbers(%s, %d) mismatch. wanted: %s, got: %v.", test.data, test.firstMove, test.wanted, values)
+		}
+	}
+
+	collection, _ := ParseSgf("(;SZ[5];B[ff])")
+	if _, err := MoveNumbers(collection.GameTrees[0].MainLine(), 0); err == nil {
+		t.Errorf("MoveNumbers did not return error.")
+	}
+}
@@ -0,0 +1,463 @@
+/*
+Package gib converts Tygem GIB game records to sgf Collections and back.
+
+GIB file contains a header section with the game information and a game section with the moves:
+
+	\HS
+	\[GAMEBLACKNAME=Lee Sedol (9D)\]
+	\[GAMEWHITENAME=Gu Li (9D)\]
+	\[GAMEINFOMAIN=GBKIND:3,GRLT:4,ZIPSU:0,GONGJE:65,\]
+	\[GAMEDATE=2014- 1-26-13-05-00\]
+	\HE
+	\GS
+	2 1 0
+	119 0 &4
+	INI 0 1 0 &4
+	STO 0 2 1 15 3
+	STO 0 3 2 3 15
+	\GE
+
+Game information is mapped to the standard game info properties:
+
+	GAMEBLACKNAME, GAMEWHITENAME   PB, BR, PW, WR
+	GAMENAME, GAMEPLACE            EV, PC
+	GAMEDATE                       DT
+	GAMEINFOMAIN GONGJE            KM (komi multiplied by ten)
+	GAMEINFOMAIN GRLT, ZIPSU       RE (result code and score multiplied by ten)
+	INI                            HA and the handicap stones as AB
+
+GIB boards are always 19x19.
+*/
+package gib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+const boardSize = 19
+
+// Result codes of GAMEINFOMAIN GRLT.
+const (
+	resultBlackScore  = 0
+	resultWhiteScore  = 1
+	resultBlackResign = 3
+	resultWhiteResign = 4
+	resultBlackTime   = 7
+	resultWhiteTime   = 8
+)
+
+var readFileFunc func(string) ([]byte, error) = ioutil.ReadFile
+
+// Parse given filename as a GIB file.
+func ParseFile(filename string) (*sgf.Collection, error) {
+	bytes, err := readFileFunc(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(string(bytes))
+}
+
+// Parse given data as a GIB file. The returned collection contains a single GameTree.
+func Parse(data string) (*sgf.Collection, error) {
+	header := map[string]string{}
+	handicap := 0
+	moves := [][]string{}
+	inHeader, inGame := false, false
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == `\HS`:
+			inHeader = true
+		case line == `\HE`:
+			inHeader = false
+		case line == `\GS`:
+			inGame = true
+		case line == `\GE`:
+			inGame = false
+		case inHeader && strings.HasPrefix(line, `\[`) && strings.HasSuffix(line, `\]`):
+			kv := strings.SplitN(line[2:len(line)-2], "=", 2)
+			if len(kv) == 2 {
+				header[kv[0]] = strings.TrimSpace(kv[1])
+			}
+		case inGame:
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+
+			switch fields[0] {
+			case "INI":
+				if len(fields) < 4 {
+					return nil, createError("Invalid INI line", i)
+				}
+
+				h, err := strconv.Atoi(fields[3])
+				if err != nil {
+					return nil, createError("Invalid handicap", i)
+				}
+
+				handicap = h
+			case "STO":
+				if len(fields) < 6 {
+					return nil, createError("Invalid STO line", i)
+				}
+
+				moves = append(moves, fields)
+			case "SKI":
+				moves = append(moves, fields)
+			}
+		}
+	}
+
+	if inHeader || inGame {
+		return nil, errors.New("Unexpected end of file")
+	}
+
+	collection, gameTree, root := sgf.NewCollection()
+	root.NewProperty("FF", "4")
+	root.NewProperty("GM", "1")
+	root.NewProperty("SZ", strconv.Itoa(boardSize))
+	addGameInfo(root, header)
+
+	if handicap >= 2 {
+		points := sgf.HandicapPoints(boardSize, handicap)
+		if points == nil {
+			return nil, errors.New(fmt.Sprintf("Invalid handicap %d", handicap))
+		}
+
+		root.NewProperty("HA", strconv.Itoa(handicap))
+		root.NewProperty("AB", sgf.CompressPoints(points)...)
+	}
+
+	// White starts in handicap games
+	color := "B"
+	if handicap >= 2 {
+		color = "W"
+	}
+
+	for _, move := range moves {
+		if move[0] == "SKI" {
+			// Pass, same color as next in turn
+			gameTree.NewNode().NewProperty(color, "")
+			color = otherColor(color)
+			continue
+		}
+
+		x, errX := strconv.Atoi(move[4])
+		y, errY := strconv.Atoi(move[5])
+		point := sgf.Point{X: x, Y: y}
+		if errX != nil || errY != nil || !point.OnBoard(boardSize, boardSize) {
+			return nil, errors.New(fmt.Sprintf("Invalid move %s", strings.Join(move, " ")))
+		}
+
+		switch move[3] {
+		case "1":
+			color = "B"
+		case "2":
+			color = "W"
+		default:
+			return nil, errors.New(fmt.Sprintf("Invalid color %s", move[3]))
+		}
+
+		gameTree.NewNode().NewProperty(color, point.String())
+		color = otherColor(color)
+	}
+
+	return collection, nil
+}
+
+func addGameInfo(root *sgf.Node, header map[string]string) {
+	if name, rank := splitPlayer(header["GAMEBLACKNAME"]); name != "" {
+		root.NewProperty("PB", name)
+		if rank != "" {
+			root.NewProperty("BR", rank)
+		}
+	}
+
+	if name, rank := splitPlayer(header["GAMEWHITENAME"]); name != "" {
+		root.NewProperty("PW", name)
+		if rank != "" {
+			root.NewProperty("WR", rank)
+		}
+	}
+
+	if event := header["GAMENAME"]; event != "" {
+		root.NewProperty("EV", event)
+	}
+
+	if place := header["GAMEPLACE"]; place != "" {
+		root.NewProperty("PC", place)
+	}
+
+	if date := parseDate(header["GAMEDATE"]); date != "" {
+		root.NewProperty("DT", date)
+	}
+
+	info := parseInfoMain(header["GAMEINFOMAIN"])
+
+	if komi, err := strconv.Atoi(info["GONGJE"]); err == nil {
+		root.NewProperty("KM", strconv.FormatFloat(float64(komi)/10, 'f', -1, 64))
+	}
+
+	if result := parseResult(info["GRLT"], info["ZIPSU"]); result != "" {
+		root.NewProperty("RE", result)
+	}
+}
+
+var playerRegexp = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)$`)
+
+// Splits "Lee Sedol (9D)" to name and rank.
+func splitPlayer(player string) (string, string) {
+	if m := playerRegexp.FindStringSubmatch(player); m != nil {
+		return m[1], strings.ToLower(m[2])
+	}
+
+	return player, ""
+}
+
+var numberRegexp = regexp.MustCompile(`\d+`)
+
+// Converts dates like "2014- 1-26-13-05-00" to "2014-01-26".
+func parseDate(date string) string {
+	numbers := numberRegexp.FindAllString(date, 3)
+	if len(numbers) < 3 {
+		return ""
+	}
+
+	year, _ := strconv.Atoi(numbers[0])
+	month, _ := strconv.Atoi(numbers[1])
+	day, _ := strconv.Atoi(numbers[2])
+
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+}
+
+// Parses "KEY:VALUE,KEY:VALUE," pairs.
+func parseInfoMain(infoMain string) map[string]string {
+	info := map[string]string{}
+
+	for _, pair := range strings.Split(infoMain, ",") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) == 2 {
+			info[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return info
+}
+
+func parseResult(code, score string) string {
+	c, err := strconv.Atoi(code)
+	if err != nil {
+		return ""
+	}
+
+	s, _ := strconv.Atoi(score)
+	points := strconv.FormatFloat(float64(s)/10, 'f', -1, 64)
+
+	switch c {
+	case resultBlackScore:
+		return "B+" + points
+	case resultWhiteScore:
+		return "W+" + points
+	case resultBlackResign:
+		return "B+R"
+	case resultWhiteResign:
+		return "W+R"
+	case resultBlackTime:
+		return "B+T"
+	case resultWhiteTime:
+		return "W+T"
+	}
+
+	return ""
+}
+
+// Converts the main line of the first GameTree in the collection to GIB format. Only moves and handicap stones are
+// supported, other setup properties return an error.
+func Gib(collection *sgf.Collection) (string, error) {
+	if len(collection.GameTrees) == 0 {
+		return "", errors.New("Collection does not contain any GameTrees")
+	}
+
+	nodes := collection.GameTrees[0].MainLine()
+	if len(nodes) == 0 {
+		return "", errors.New("GameTree does not contain any Nodes")
+	}
+
+	root := nodes[0]
+
+	if size := value(root, "SZ"); size != "" && size != strconv.Itoa(boardSize) {
+		return "", errors.New(fmt.Sprintf("Unsupported board size %s", size))
+	}
+
+	handicap, _ := strconv.Atoi(value(root, "HA"))
+
+	var buffer bytes.Buffer
+	buffer.WriteString("\\HS\n")
+	writeHeader(&buffer, root, handicap)
+	buffer.WriteString("\\HE\n")
+	buffer.WriteString("\\GS\n")
+	buffer.WriteString("2 1 0\n")
+	buffer.WriteString("119 0 &4\n")
+	buffer.WriteString(fmt.Sprintf("INI 0 1 %d &4\n", handicap))
+
+	moveNumber := 1
+	for i, node := range nodes {
+		for _, property := range node.Properties {
+			switch property.Ident {
+			case "B", "W":
+				moveNumber++
+
+				color := 1
+				if property.Ident == "W" {
+					color = 2
+				}
+
+				if property.Values[0] == "" || property.Values[0] == "tt" {
+					buffer.WriteString(fmt.Sprintf("SKI 0 %d\n", moveNumber))
+					continue
+				}
+
+				point, err := sgf.ParsePoint(property.Values[0])
+				if err != nil {
+					return "", err
+				}
+
+				buffer.WriteString(fmt.Sprintf("STO 0 %d %d %d %d\n", moveNumber, color, point.X, point.Y))
+			case "AB":
+				if i == 0 && isHandicapSetup(property, handicap) {
+					continue
+				}
+
+				return "", errors.New("Setup stones are not supported")
+			case "AW", "AE":
+				return "", errors.New("Setup stones are not supported")
+			}
+		}
+	}
+
+	buffer.WriteString("\\GE\n")
+
+	return buffer.String(), nil
+}
+
+func writeHeader(buffer *bytes.Buffer, root *sgf.Node, handicap int) {
+	writeLine := func(key, value string) {
+		if value != "" {
+			buffer.WriteString(fmt.Sprintf("\\[%s=%s\\]\n", key, value))
+		}
+	}
+
+	writeLine("GAMEBLACKNAME", joinPlayer(value(root, "PB"), value(root, "BR")))
+	writeLine("GAMEWHITENAME", joinPlayer(value(root, "PW"), value(root, "WR")))
+	writeLine("GAMENAME", value(root, "EV"))
+	writeLine("GAMEPLACE", value(root, "PC"))
+	writeLine("GAMEDATE", value(root, "DT"))
+
+	info := "GBKIND:3,"
+	if code, score, ok := resultCode(value(root, "RE")); ok {
+		info += fmt.Sprintf("GRLT:%d,ZIPSU:%d,", code, score)
+	}
+
+	if komi, err := strconv.ParseFloat(value(root, "KM"), 64); err == nil {
+		info += fmt.Sprintf("GONGJE:%d,", int(math.Round(komi*10)))
+	}
+
+	writeLine("GAMEINFOMAIN", info)
+}
+
+func joinPlayer(name, rank string) string {
+	if name != "" && rank != "" {
+		return fmt.Sprintf("%s (%s)", name, strings.ToUpper(rank))
+	}
+
+	return name
+}
+
+func resultCode(result string) (int, int, bool) {
+	switch result {
+	case "B+R", "B+Resign":
+		return resultBlackResign, 0, true
+	case "W+R", "W+Resign":
+		return resultWhiteResign, 0, true
+	case "B+T", "B+Time":
+		return resultBlackTime, 0, true
+	case "W+T", "W+Time":
+		return resultWhiteTime, 0, true
+	}
+
+	if len(result) > 2 && result[1] == '+' {
+		score, err := strconv.ParseFloat(result[2:], 64)
+		if err != nil {
+			return 0, 0, false
+		}
+
+		switch result[0] {
+		case 'B':
+			return resultBlackScore, int(math.Round(score * 10)), true
+		case 'W':
+			return resultWhiteScore, int(math.Round(score * 10)), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// Check if the AB property contains exactly the handicap stones.
+func isHandicapSetup(property *sgf.Property, handicap int) bool {
+	points, err := sgf.ParsePointList(property.Values...)
+	if err != nil {
+		return false
+	}
+
+	handicapPoints := sgf.HandicapPoints(boardSize, handicap)
+	if len(points) != len(handicapPoints) {
+		return false
+	}
+
+	expected := map[sgf.Point]bool{}
+	for _, point := range handicapPoints {
+		expected[point] = true
+	}
+
+	for _, point := range points {
+		if !expected[point] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the first value of the property or an empty string.
+func value(node *sgf.Node, ident string) string {
+	if property := node.Property(ident); property != nil && len(property.Values) > 0 {
+		return property.Values[0]
+	}
+
+	return ""
+}
+
+func otherColor(color string) string {
+	if color == "B" {
+		return "W"
+	}
+
+	return "B"
+}
+
+func createError(msg string, line int) error {
+	return errors.New(fmt.Sprintf("%s [line %d]", msg, line+1))
+}
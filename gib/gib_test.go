@@ -0,0 +1,138 @@
+package gib
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+func TestParseFile(t *testing.T) {
+	var tests = []struct {
+		filename string
+		wanted   string
+	}{
+		{"testdata/jubango.gib",
+			"(;FF[4]GM[1]SZ[19]PB[Lee Sedol]BR[9d]PW[Gu Li]WR[9d]EV[Jubango Game 1]PC[Beijing]DT[2014-01-26]KM[7.5]RE[W+R]" +
+				";B[pd];W[dp];B[qp];W[];B[cd])"},
+		{"testdata/handicap.gib",
+			"(;FF[4]GM[1]SZ[19]PB[Amateur]BR[3k]PW[Teacher]KM[0.5]RE[B+3.5]HA[4]AB[dd][pd][dp][pp]" +
+				";W[qj];B[pj])"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseFile(test.filename)
+		if err != nil {
+			t.Errorf("ParseFile(%s) returned error: %s", test.filename, err)
+			continue
+		}
+
+		if sgf := collection.Sgf(sgf.NoNewLinesSgfFormat); sgf != test.wanted {
+			t.Errorf("ParseFile(%s) mismatch. Got: %s.", test.filename, sgf)
+		}
+	}
+}
+
+func TestReadFileError(t *testing.T) {
+	oldReadFileFunc := readFileFunc
+	defer func() {
+		readFileFunc = oldReadFileFunc
+	}()
+
+	readFileFunc = func(filename string) ([]byte, error) {
+		return nil, errors.New("")
+	}
+
+	if _, err := ParseFile("foo"); err == nil {
+		t.Errorf("ParseFile did not return error.")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var errTests = []string{
+		"\\HS\n",
+		"\\GS\nSTO 0 2 1 15\n\\GE\n",
+		"\\GS\nSTO 0 2 1 19 3\n\\GE\n",
+		"\\GS\nSTO 0 2 3 15 3\n\\GE\n",
+		"\\GS\nINI 0 1\n\\GE\n",
+		"\\GS\nINI 0 1 12 &4\n\\GE\n",
+	}
+
+	for _, test := range errTests {
+		if _, err := Parse(test); err == nil {
+			t.Errorf("Parse(%q) did not return error.", test)
+		}
+	}
+
+	wanted := "Invalid STO line [line 3]"
+	if _, err := Parse("\\HS\n\\GS\nSTO 0 2 1\n\\GE\n"); err == nil || err.Error() != wanted {
+		t.Errorf("Parse error mismatch. Wanted: %s, got: %v", wanted, err)
+	}
+}
+
+func TestGibRoundTrip(t *testing.T) {
+	for _, filename := range []string{"testdata/jubango.gib", "testdata/handicap.gib"} {
+		collection, err := ParseFile(filename)
+		if err != nil {
+			t.Fatalf("ParseFile(%s) returned error: %s", filename, err)
+		}
+
+		gib, err := Gib(collection)
+		if err != nil {
+			t.Errorf("Gib(%s) returned error: %s", filename, err)
+			continue
+		}
+
+		parsed, err := Parse(gib)
+		if err != nil {
+			t.Errorf("Parse(Gib(%s)) returned error: %s", filename, err)
+			continue
+		}
+
+		if parsed.Sgf(sgf.NoNewLinesSgfFormat) != collection.Sgf(sgf.NoNewLinesSgfFormat) {
+			t.Errorf("Parse(Gib(%s)) mismatch. Got: %s.", filename, parsed.Sgf(sgf.NoNewLinesSgfFormat))
+		}
+	}
+}
+
+func TestGibGameInfo(t *testing.T) {
+	collection, err := sgf.ParseSgf("(;KM[6.99]RE[W+0.57])")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	gib, err := Gib(collection)
+	if err != nil {
+		t.Fatalf("Gib returned error: %s", err)
+	}
+
+	// Scores are rounded, not truncated, to tenths
+	if wanted := "\\[GAMEINFOMAIN=GBKIND:3,GRLT:1,ZIPSU:6,GONGJE:70,\\]\n"; !strings.Contains(gib, wanted) {
+		t.Errorf("Gib game info mismatch. Wanted: %s, got: %s", wanted, gib)
+	}
+}
+
+func TestGibErrors(t *testing.T) {
+	var errTests = []string{
+		"(;SZ[13];B[aa])",
+		"(;AW[aa];B[bb])",
+		"(;HA[2]AB[aa];W[bb])",
+	}
+
+	for _, test := range errTests {
+		collection, err := sgf.ParseSgf(test)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error.", test)
+		}
+
+		if _, err := Gib(collection); err == nil {
+			t.Errorf("Gib(%s) did not return error.", test)
+		}
+	}
+
+	collection := &sgf.Collection{GameTrees: []*sgf.GameTree{{}}}
+	if _, err := Gib(collection); err == nil {
+		t.Errorf("Gib of a GameTree without Nodes did not return error.")
+	}
+}
@@ -0,0 +1,93 @@
+package sgf
+
+// Placement order of the handicap stones: upper right, lower left, lower right and upper left corner, center, left
+// and right side, top and bottom side.
+var handicapOrder = []struct {
+	x, y int // -1 = near edge, 0 = center, 1 = far edge
+}{
+	{1, -1}, {-1, 1}, {1, 1}, {-1, -1}, {0, 0}, {-1, 0}, {1, 0}, {0, -1}, {0, 1},
+}
+
+// Returns the fixed handicap stone positions for the given board size and handicap. Stones are placed on the star
+// points as in Japanese rules: with 6 and 8 stones the center point is left empty. Returns nil if the handicap is not
+// possible on the board.
+func HandicapPoints(size, handicap int) []Point {
+	if handicap < 2 || size < 7 {
+		return nil
+	}
+
+	// Star points are on the fourth line, on boards smaller than 13x13 on the third line
+	edge := 3
+	if size < 13 {
+		edge = 2
+	}
+
+	// Even sized boards do not have center and side star points
+	maxHandicap := 9
+	if size%2 == 0 {
+		maxHandicap = 4
+	}
+
+	if handicap > maxHandicap {
+		return nil
+	}
+
+	coordinate := func(c int) int {
+		switch c {
+		case -1:
+			return edge
+		case 1:
+			return size - 1 - edge
+		}
+
+		return size / 2
+	}
+
+	order := handicapOrder[:handicap]
+	switch handicap {
+	case 6, 8:
+		// Center is left empty, side points are used instead
+		order = append(append([]struct{ x, y int }{}, handicapOrder[:4]...), handicapOrder[5:handicap+1]...)
+	}
+
+	points := []Point{}
+	for _, o := range order {
+		points = append(points, Point{coordinate(o.x), coordinate(o.y)})
+	}
+
+	return points
+}
+
+// Returns the star points (hoshi) of the board. Boards smaller than 7x7 do not have star points. Center point is a
+// star point on odd sized boards and side star points are used on odd sized boards from 15x15.
+func StarPoints(width, height int) []Point {
+	if width < 7 || height < 7 {
+		return []Point{}
+	}
+
+	edge := 3
+	if width < 13 || height < 13 {
+		edge = 2
+	}
+
+	coordinates := func(size int) []int {
+		if size%2 == 1 && size >= 15 {
+			return []int{edge, size / 2, size - 1 - edge}
+		}
+
+		return []int{edge, size - 1 - edge}
+	}
+
+	points := []Point{}
+	for _, y := range coordinates(height) {
+		for _, x := range coordinates(width) {
+			points = append(points, Point{x, y})
+		}
+	}
+
+	if width%2 == 1 && height%2 == 1 && (width < 15 || height < 15) {
+		points = append(points, Point{width / 2, height / 2})
+	}
+
+	return points
+}
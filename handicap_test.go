@@ -0,0 +1,64 @@
+package sgf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHandicapPoints(t *testing.T) {
+	var tests = []struct {
+		size     int
+		handicap int
+		wanted   []string
+	}{
+		{19, 1, nil},
+		{19, 2, []string{"pd", "dp"}},
+		{19, 5, []string{"pd", "dp", "pp", "dd", "jj"}},
+		{19, 6, []string{"pd", "dp", "pp", "dd", "dj", "pj"}},
+		{19, 8, []string{"pd", "dp", "pp", "dd", "dj", "pj", "jd", "jp"}},
+		{19, 9, []string{"pd", "dp", "pp", "dd", "jj", "dj", "pj", "jd", "jp"}},
+		{19, 10, nil},
+		{9, 3, []string{"gc", "cg", "gg"}},
+		{10, 5, nil},
+	}
+
+	for _, test := range tests {
+		points := HandicapPoints(test.size, test.handicap)
+		values := pointValues(points)
+
+		if len(values) != len(test.wanted) {
+			t.Errorf("HandicapPoints(%d, %d) mismatch. wanted: %v, got: %v.", test.size, test.handicap, test.wanted, values)
+			continue
+		}
+
+		for i, value := range values {
+			if value != test.wanted[i] {
+				t.Errorf("HandicapPoints(%d, %d) mismatch. wanted: %v, got: %v.", test.size, test.handicap, test.wanted, values)
+				break
+			}
+		}
+	}
+}
+
+func TestStarPoints(t *testing.T) {
+	var tests = []struct {
+		width  int
+		height int
+		wanted []string
+	}{
+		{19, 19, []string{"dd", "jd", "pd", "dj", "jj", "pj", "dp", "jp", "pp"}},
+		{13, 13, []string{"dd", "jd", "dj", "jj", "gg"}},
+		{9, 9, []string{"cc", "gc", "cg", "gg", "ee"}},
+		{10, 10, []string{"cc", "hc", "ch", "hh"}},
+		{19, 13, []string{"dd", "jd", "pd", "dj", "jj", "pj", "jg"}},
+		{6, 6, []string{}},
+	}
+
+	for _, test := range tests {
+		values := pointValues(StarPoints(test.width, test.height))
+
+		if fmt.Sprint(values) != fmt.Sprint(test.wanted) {
+			t.Errorf("StarPoints(%d, %d) mismatch. wanted: %v, got: %v.", test.width, test.height, test.wanted, values)
+		}
+	}
+}
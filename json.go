@@ -0,0 +1,492 @@
+package sgf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// JSON schema
+//
+// Collection, GameTree, Node and Property implement json.Marshaler and json.Unmarshaler using the following schema.
+// Properties are kept in arrays to keep their order:
+//
+//	{"gameTrees": [
+//	    {"nodes": [
+//	        {"properties": [{"ident": "FF", "values": ["4"]}, {"ident": "SZ", "values": ["19"]}]},
+//	        {"properties": [{"ident": "B", "values": ["pd"]}]}
+//	     ],
+//	     "gameTrees": []}
+//	]}
+//
+// Flat schema (JSONOptions.Flat) lists all the Nodes in depth-first order with the id of the parent Node. Root Nodes
+// of the top-level GameTrees have null parent:
+//
+//	{"nodes": [
+//	    {"id": 0, "parent": null, "properties": [{"ident": "FF", "values": ["4"]}]},
+//	    {"id": 1, "parent": 0, "properties": [{"ident": "B", "values": ["pd"]}]}
+//	]}
+//
+// Values are strings as they are stored in Property.Values. With JSONOptions.Typed the values are converted using the
+// property registry:
+//   - Number, Real and Double are JSON numbers.
+//   - Text and SimpleText are decoded strings (see Property.DecodedValues).
+//   - Point, Stone and Move are objects {"x": 3, "y": 15}. Pass move and empty values are null.
+//   - Compressed point lists are expanded to single points.
+//   - Composed values are arrays of two values.
+//   - Color and values of unknown properties are strings.
+//
+// Both the value forms are accepted when reading JSON.
+
+// Options for JSON conversion.
+type JSONOptions struct {
+	Typed  bool   // convert values using the property registry
+	Flat   bool   // use the flat node list schema
+	Indent string // indentation of the nested elements, compact JSON if empty
+}
+
+type jsonCollection struct {
+	GameTrees []jsonGameTree `json:"gameTrees"`
+	Nodes     []jsonFlatNode `json:"nodes,omitempty"` // only used when reading
+}
+
+type jsonGameTree struct {
+	Nodes     []jsonNode     `json:"nodes"`
+	GameTrees []jsonGameTree `json:"gameTrees"`
+}
+
+type jsonNode struct {
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonProperty struct {
+	Ident  string        `json:"ident"`
+	Values []interface{} `json:"values"`
+}
+
+type jsonFlatCollection struct {
+	Nodes []jsonFlatNode `json:"nodes"`
+}
+
+type jsonFlatNode struct {
+	ID         int            `json:"id"`
+	Parent     *int           `json:"parent"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Converts the collection to JSON using the given options.
+func (collection *Collection) JSON(options JSONOptions) ([]byte, error) {
+	var v interface{}
+
+	if options.Flat {
+		flat := jsonFlatCollection{Nodes: []jsonFlatNode{}}
+		for _, gameTree := range collection.GameTrees {
+			flat.Nodes = appendFlatNodes(flat.Nodes, gameTree, nil, options.Typed)
+		}
+		v = flat
+	} else {
+		gameTrees := []jsonGameTree{}
+		for _, gameTree := range collection.GameTrees {
+			gameTrees = append(gameTrees, toJSONGameTree(gameTree, options.Typed))
+		}
+		v = jsonCollection{GameTrees: gameTrees}
+	}
+
+	if options.Indent != "" {
+		return json.MarshalIndent(v, "", options.Indent)
+	}
+
+	return json.Marshal(v)
+}
+
+// Parse the given JSON data. Both the tree and the flat schema are accepted as well as typed values.
+func ParseJSON(data []byte) (*Collection, error) {
+	var c jsonCollection
+	if err := unmarshalJSON(data, &c); err != nil {
+		return nil, err
+	}
+
+	if c.GameTrees == nil && c.Nodes != nil {
+		return fromJSONFlatNodes(c.Nodes)
+	}
+
+	collection := &Collection{}
+	for _, gameTree := range c.GameTrees {
+		gt, err := fromJSONGameTree(gameTree)
+		if err != nil {
+			return nil, err
+		}
+
+		collection.AddGameTree(gt)
+	}
+
+	return collection, nil
+}
+
+//
+// json.Marshaler and json.Unmarshaler
+//
+
+func (collection *Collection) MarshalJSON() ([]byte, error) {
+	return collection.JSON(JSONOptions{})
+}
+
+func (collection *Collection) UnmarshalJSON(data []byte) error {
+	c, err := ParseJSON(data)
+	if err != nil {
+		return err
+	}
+
+	*collection = *c
+	return nil
+}
+
+func (gameTree *GameTree) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONGameTree(gameTree, false))
+}
+
+func (gameTree *GameTree) UnmarshalJSON(data []byte) error {
+	var gt jsonGameTree
+	if err := unmarshalJSON(data, &gt); err != nil {
+		return err
+	}
+
+	g, err := fromJSONGameTree(gt)
+	if err != nil {
+		return err
+	}
+
+	*gameTree = *g
+	return nil
+}
+
+func (node *Node) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONNode(node, false))
+}
+
+func (node *Node) UnmarshalJSON(data []byte) error {
+	var n jsonNode
+	if err := unmarshalJSON(data, &n); err != nil {
+		return err
+	}
+
+	nn, err := fromJSONNode(n.Properties)
+	if err != nil {
+		return err
+	}
+
+	*node = *nn
+	return nil
+}
+
+func (property *Property) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONProperty(property, false))
+}
+
+func (property *Property) UnmarshalJSON(data []byte) error {
+	var p jsonProperty
+	if err := unmarshalJSON(data, &p); err != nil {
+		return err
+	}
+
+	pp, err := fromJSONProperty(p)
+	if err != nil {
+		return err
+	}
+
+	*property = *pp
+	return nil
+}
+
+// Unmarshals numbers as json.Number so that they can be written back as they were.
+func unmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+//
+// To JSON
+//
+
+func toJSONGameTree(gameTree *GameTree, typed bool) jsonGameTree {
+	gt := jsonGameTree{Nodes: []jsonNode{}, GameTrees: []jsonGameTree{}}
+
+	for _, node := range gameTree.Nodes {
+		gt.Nodes = append(gt.Nodes, toJSONNode(node, typed))
+	}
+
+	for _, childGameTree := range gameTree.GameTrees {
+		gt.GameTrees = append(gt.GameTrees, toJSONGameTree(childGameTree, typed))
+	}
+
+	return gt
+}
+
+func toJSONNode(node *Node, typed bool) jsonNode {
+	n := jsonNode{Properties: []jsonProperty{}}
+
+	for _, property := range node.Properties {
+		n.Properties = append(n.Properties, toJSONProperty(property, typed))
+	}
+
+	return n
+}
+
+func toJSONProperty(property *Property, typed bool) jsonProperty {
+	p := jsonProperty{Ident: property.Ident, Values: []interface{}{}}
+
+	if !typed {
+		for _, value := range property.Values {
+			p.Values = append(p.Values, value)
+		}
+
+		return p
+	}
+
+	info := property.Info()
+	for _, value := range property.DecodedValues() {
+		if info.Composed != ValueUnknown {
+			if first, second, ok := Compose(value).Split(); ok {
+				p.Values = append(p.Values, []interface{}{typedValue(info.Value, first), typedValue(info.Composed, second)})
+				continue
+			}
+		}
+
+		// Expand compressed point lists
+		if info.List && info.Composed == ValueUnknown && (info.Value == ValuePoint || info.Value == ValueStone) {
+			if points, err := ParsePointList(value); err == nil && len(points) > 0 {
+				for _, point := range points {
+					p.Values = append(p.Values, jsonPoint{point.X, point.Y})
+				}
+				continue
+			}
+		}
+
+		p.Values = append(p.Values, typedValue(info.Value, value))
+	}
+
+	return p
+}
+
+// Converts the value to a typed JSON value. Values which do not match their type are kept as strings.
+func typedValue(valueType ValueType, value string) interface{} {
+	switch valueType {
+	case ValueNone:
+		if value == "" {
+			return nil
+		}
+	case ValueNumber, ValueDouble:
+		if _, err := strconv.Atoi(value); err == nil {
+			return json.Number(value)
+		}
+	case ValueReal:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	case ValuePoint, ValueStone, ValueMove:
+		// Empty value is a pass or an empty list
+		if value == "" || (valueType == ValueMove && value == "tt") {
+			return nil
+		}
+
+		if point, err := ParsePoint(value); err == nil {
+			return jsonPoint{point.X, point.Y}
+		}
+	}
+
+	return value
+}
+
+func appendFlatNodes(nodes []jsonFlatNode, gameTree *GameTree, parent *int, typed bool) []jsonFlatNode {
+	for _, node := range gameTree.Nodes {
+		id := len(nodes)
+		nodes = append(nodes, jsonFlatNode{id, parent, toJSONNode(node, typed).Properties})
+		parent = &id
+	}
+
+	for _, childGameTree := range gameTree.GameTrees {
+		nodes = appendFlatNodes(nodes, childGameTree, parent, typed)
+	}
+
+	return nodes
+}
+
+//
+// From JSON
+//
+
+func fromJSONGameTree(gt jsonGameTree) (*GameTree, error) {
+	gameTree := &GameTree{}
+
+	for _, n := range gt.Nodes {
+		node, err := fromJSONNode(n.Properties)
+		if err != nil {
+			return nil, err
+		}
+
+		gameTree.AddNode(node)
+	}
+
+	for _, childGameTree := range gt.GameTrees {
+		child, err := fromJSONGameTree(childGameTree)
+		if err != nil {
+			return nil, err
+		}
+
+		gameTree.AddGameTree(child)
+	}
+
+	return gameTree, nil
+}
+
+func fromJSONNode(properties []jsonProperty) (*Node, error) {
+	node := &Node{}
+
+	for _, p := range properties {
+		property, err := fromJSONProperty(p)
+		if err != nil {
+			return nil, err
+		}
+
+		node.AddProperty(property)
+	}
+
+	return node, nil
+}
+
+func fromJSONProperty(p jsonProperty) (*Property, error) {
+	property := &Property{Ident: p.Ident}
+
+	for _, v := range p.Values {
+		value, err := fromJSONValue(v, true)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid value of property %s: %s", p.Ident, err))
+		}
+
+		property.Values = append(property.Values, value)
+	}
+
+	return property, nil
+}
+
+// Converts a plain or a typed JSON value to a property value.
+func fromJSONValue(v interface{}, allowComposed bool) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return "", errors.New("boolean value")
+	case map[string]interface{}:
+		x, okX := v["x"].(json.Number)
+		y, okY := v["y"].(json.Number)
+		if !okX || !okY {
+			return "", errors.New("point must have numeric x and y")
+		}
+
+		px, errX := x.Int64()
+		py, errY := y.Int64()
+		if errX != nil || errY != nil || px < 0 || py < 0 || px > 51 || py > 51 {
+			return "", errors.New(fmt.Sprintf("invalid point %s, %s", x, y))
+		}
+
+		return Point{int(px), int(py)}.String(), nil
+	case []interface{}:
+		if !allowComposed || len(v) != 2 {
+			return "", errors.New("composed value must have two parts")
+		}
+
+		first, err := fromJSONValue(v[0], false)
+		if err != nil {
+			return "", err
+		}
+
+		second, err := fromJSONValue(v[1], false)
+		if err != nil {
+			return "", err
+		}
+
+		return string(NewCompose(first, second)), nil
+	}
+
+	return "", errors.New(fmt.Sprintf("unsupported value %v", v))
+}
+
+// Builds the collection from a flat list of Nodes. Node with a single child continues the same GameTree, Node with
+// multiple children starts a child GameTree for each of them.
+func fromJSONFlatNodes(flatNodes []jsonFlatNode) (*Collection, error) {
+	nodes := map[int]*Node{}
+	children := map[int][]int{}
+	roots := []int{}
+
+	for _, n := range flatNodes {
+		if _, ok := nodes[n.ID]; ok {
+			return nil, errors.New(fmt.Sprintf("Duplicate node id %d", n.ID))
+		}
+
+		node, err := fromJSONNode(n.Properties)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[n.ID] = node
+
+		if n.Parent == nil {
+			roots = append(roots, n.ID)
+		} else {
+			children[*n.Parent] = append(children[*n.Parent], n.ID)
+		}
+	}
+
+	for parent := range children {
+		if _, ok := nodes[parent]; !ok {
+			return nil, errors.New(fmt.Sprintf("Unknown parent node id %d", parent))
+		}
+	}
+
+	visited := 0
+	var buildGameTree func(id int) *GameTree
+	buildGameTree = func(id int) *GameTree {
+		gameTree := &GameTree{}
+
+		for {
+			gameTree.AddNode(nodes[id])
+			visited++
+
+			if len(children[id]) != 1 {
+				break
+			}
+
+			id = children[id][0]
+		}
+
+		for _, child := range children[id] {
+			gameTree.AddGameTree(buildGameTree(child))
+		}
+
+		return gameTree
+	}
+
+	collection := &Collection{}
+	for _, root := range roots {
+		collection.AddGameTree(buildGameTree(root))
+	}
+
+	if visited != len(flatNodes) {
+		return nil, errors.New("Nodes contain a cycle")
+	}
+
+	return collection, nil
+}
@@ -0,0 +1,124 @@
+package sgf
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	collection, err := ParseSgf("(;FF[4]SZ[19];B[pd](;W[dp])(;W[dd]))")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error.")
+	}
+
+	var tests = []struct {
+		options JSONOptions
+		wanted  string
+	}{
+		{JSONOptions{},
+			`{"gameTrees":[{"nodes":[{"properties":[{"ident":"FF","values":["4"]},{"ident":"SZ","values":["19"]}]},` +
+				`{"properties":[{"ident":"B","values":["pd"]}]}],"gameTrees":[` +
+				`{"nodes":[{"properties":[{"ident":"W","values":["dp"]}]}],"gameTrees":[]},` +
+				`{"nodes":[{"properties":[{"ident":"W","values":["dd"]}]}],"gameTrees":[]}]}]}`},
+		{JSONOptions{Typed: true, Flat: true},
+			`{"nodes":[{"id":0,"parent":null,"properties":[{"ident":"FF","values":[4]},{"ident":"SZ","values":[19]}]},` +
+				`{"id":1,"parent":0,"properties":[{"ident":"B","values":[{"x":15,"y":3}]}]},` +
+				`{"id":2,"parent":1,"properties":[{"ident":"W","values":[{"x":3,"y":15}]}]},` +
+				`{"id":3,"parent":1,"properties":[{"ident":"W","values":[{"x":3,"y":3}]}]}]}`},
+	}
+
+	for _, test := range tests {
+		data, err := collection.JSON(test.options)
+		if err != nil {
+			t.Errorf("JSON(%v) returned error.", test.options)
+			continue
+		}
+
+		if string(data) != test.wanted {
+			t.Errorf("JSON(%v) mismatch. Got: %s.", test.options, data)
+		}
+
+		parsed, err := ParseJSON(data)
+		if err != nil {
+			t.Errorf("ParseJSON(%s) returned error: %s", data, err)
+			continue
+		}
+
+		if sgf := parsed.Sgf(NoNewLinesSgfFormat); sgf != collection.Sgf(NoNewLinesSgfFormat) {
+			t.Errorf("ParseJSON(%s) mismatch. Got: %s.", data, sgf)
+		}
+	}
+}
+
+func TestJSONTypedValues(t *testing.T) {
+	data := "(;SZ[19:13]KM[6.50]PL[W]LB[dd:a\\:b]AB[aa:ba]DD[]C[a\tb];B[]KO[])"
+
+	collection, err := ParseSgf(data)
+	if err != nil {
+		t.Fatalf("ParseSgf returned error.")
+	}
+
+	j, err := collection.JSON(JSONOptions{Typed: true})
+	if err != nil {
+		t.Fatalf("JSON() returned error.")
+	}
+
+	wanted := `{"gameTrees":[{"nodes":[{"properties":[{"ident":"SZ","values":[[19,13]]},{"ident":"KM","values":[6.5]},` +
+		`{"ident":"PL","values":["W"]},{"ident":"LB","values":[[{"x":3,"y":3},"a:b"]]},` +
+		`{"ident":"AB","values":[{"x":0,"y":0},{"x":1,"y":0}]},{"ident":"DD","values":[null]},{"ident":"C","values":["a b"]}]},` +
+		`{"properties":[{"ident":"B","values":[null]},{"ident":"KO","values":[null]}]}],"gameTrees":[]}]}`
+
+	if string(j) != wanted {
+		t.Errorf("JSON() mismatch. Got: %s.", j)
+	}
+
+	parsed, err := ParseJSON(j)
+	if err != nil {
+		t.Fatalf("ParseJSON returned error: %s", err)
+	}
+
+	if sgf := parsed.Sgf(NoNewLinesSgfFormat); sgf != "(;SZ[19:13]KM[6.5]PL[W]LB[dd:a\\:b]AB[aa][ba]DD[]C[a b];B[]KO[])" {
+		t.Errorf("ParseJSON() mismatch. Got: %s.", sgf)
+	}
+}
+
+func TestJSONMarshaler(t *testing.T) {
+	collection, _ := ParseSgf("(;FF[4]C[foo];B[aa])")
+
+	data, err := json.Marshal(collection.GameTrees[0].Nodes[0])
+	if err != nil || string(data) != `{"properties":[{"ident":"FF","values":["4"]},{"ident":"C","values":["foo"]}]}` {
+		t.Errorf("json.Marshal(node) mismatch. Got: %s.", data)
+	}
+
+	var c Collection
+	if err := json.Unmarshal([]byte(`{"gameTrees":[{"nodes":[{"properties":[{"ident":"FF","values":["4"]}]}]}]}`), &c); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %s", err)
+	}
+
+	if sgf := c.Sgf(NoNewLinesSgfFormat); sgf != "(;FF[4])" {
+		t.Errorf("json.Unmarshal mismatch. Got: %s.", sgf)
+	}
+
+	var p Property
+	if err := json.Unmarshal([]byte(`{"ident":"SZ","values":[19]}`), &p); err != nil || p.Ident != "SZ" || p.Values[0] != "19" {
+		t.Errorf("json.Unmarshal(property) mismatch. Got: %v.", p)
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	var errTests = []string{
+		`{`,
+		`{"gameTrees":[{"nodes":[{"properties":[{"ident":"B","values":[true]}]}]}]}`,
+		`{"gameTrees":[{"nodes":[{"properties":[{"ident":"B","values":[{"x":1}]}]}]}]}`,
+		`{"gameTrees":[{"nodes":[{"properties":[{"ident":"SZ","values":[[1,2,3]]}]}]}]}`,
+		`{"nodes":[{"id":0,"parent":null},{"id":0,"parent":0}]}`,
+		`{"nodes":[{"id":0,"parent":5}]}`,
+		`{"nodes":[{"id":0,"parent":null},{"id":1,"parent":2},{"id":2,"parent":1}]}`,
+	}
+
+	for _, test := range errTests {
+		if _, err := ParseJSON([]byte(test)); err == nil {
+			t.Errorf("ParseJSON(%s) did not return error.", test)
+		}
+	}
+}
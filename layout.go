@@ -0,0 +1,173 @@
+package sgf
+
+import (
+	"strings"
+)
+
+// Layout contains the original formatting of a parsed collection: whitespace between the tokens, line breaks and the
+// original escaping of the property values. It is used by Collection.SgfWithLayout to write the untouched parts of
+// the collection byte-for-byte as they were parsed. Property values are kept as bytes, so this holds also for data
+// that is not valid UTF-8, such as ISO-8859-1 files.
+type Layout struct {
+	gameTrees  map[*GameTree]*gameTreeLayout
+	nodes      map[*Node]string // whitespace before the Node
+	properties map[*Property]*propertyLayout
+	trailing   string // whitespace after the last GameTree
+	data       string
+	offsets    map[interface{}]int // offsets of the Nodes and the Properties in the data
+}
+
+type gameTreeLayout struct {
+	space string // whitespace before '('
+	end   string // whitespace before ')'
+}
+
+type propertyLayout struct {
+	space  string   // whitespace before the ident
+	ident  string   // original ident
+	values []string // original values
+	raw    string   // ident and values as they were written
+}
+
+// Parse given data as a SGF file and record the original formatting. Use Collection.SgfWithLayout with the returned
+// layout to write the collection without reformatting the untouched parts.
+func ParseSgfWithLayout(data string) (*Collection, *Layout, error) {
+	lexemes, err := lexicalAnalysis(data)
+	if err != nil {
+		return nil, nil, locateSyntaxError(err, data)
+	}
+
+	layout := &Layout{
+		gameTrees:  map[*GameTree]*gameTreeLayout{},
+		nodes:      map[*Node]string{},
+		properties: map[*Property]*propertyLayout{},
+		trailing:   data[len(strings.TrimRight(data, " \t\v\r\n")):],
+		data:       data,
+		offsets:    map[interface{}]int{},
+	}
+
+	collection, err := parse(lexemes, layout)
+	if err != nil {
+		return nil, nil, locateSyntaxError(err, data)
+	}
+
+	return collection, layout, nil
+}
+
+// Parse given filename as a SGF file and record the original formatting.
+func ParseSgfFileWithLayout(filename string) (*Collection, *Layout, error) {
+	bytes, err := readFileFunc(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ParseSgfWithLayout(string(bytes))
+}
+
+//
+// Recording, all the methods do nothing if the layout is nil.
+//
+
+func (layout *Layout) addGameTree(gameTree *GameTree, l lexeme) {
+	if layout != nil {
+		layout.gameTrees[gameTree] = &gameTreeLayout{space: l.space}
+	}
+}
+
+func (layout *Layout) endGameTree(gameTree *GameTree, l lexeme) {
+	if layout != nil {
+		layout.gameTrees[gameTree].end = l.space
+	}
+}
+
+func (layout *Layout) addNode(node *Node, l lexeme) {
+	if layout != nil {
+		layout.nodes[node] = l.space
+		layout.offsets[node] = l.offset
+	}
+}
+
+func (layout *Layout) addProperty(property *Property, l lexeme) {
+	if layout != nil {
+		layout.properties[property] = &propertyLayout{space: l.space, ident: l.data, raw: l.data}
+		layout.offsets[property] = l.offset
+	}
+}
+
+func (layout *Layout) addValue(property *Property, l lexeme) {
+	if layout != nil {
+		propertyLayout := layout.properties[property]
+		propertyLayout.values = append(propertyLayout.values, property.Values[len(property.Values)-1])
+		propertyLayout.raw += l.space + "[" + l.raw + "]"
+	}
+}
+
+//
+// Lookup, all the methods return nothing if the layout is nil.
+//
+
+func (layout *Layout) gameTree(gameTree *GameTree) *gameTreeLayout {
+	if layout == nil {
+		return nil
+	}
+
+	return layout.gameTrees[gameTree]
+}
+
+func (layout *Layout) node(node *Node) (string, bool) {
+	if layout == nil {
+		return "", false
+	}
+
+	space, ok := layout.nodes[node]
+	return space, ok
+}
+
+func (layout *Layout) property(property *Property) *propertyLayout {
+	if layout == nil {
+		return nil
+	}
+
+	return layout.properties[property]
+}
+
+// Returns the line and the column, both counted from 1, where the Node (its ';') starts in the parsed data. The last
+// return value is false if the Node was not parsed with this layout.
+func (layout *Layout) NodePosition(node *Node) (int, int, bool) {
+	return layout.position(node)
+}
+
+// Returns the line and the column, both counted from 1, where the ident of the Property starts in the parsed data. The
+// last return value is false if the Property was not parsed with this layout.
+func (layout *Layout) PropertyPosition(property *Property) (int, int, bool) {
+	return layout.position(property)
+}
+
+func (layout *Layout) position(v interface{}) (int, int, bool) {
+	if layout == nil {
+		return 0, 0, false
+	}
+
+	offset, ok := layout.offsets[v]
+	if !ok {
+		return 0, 0, false
+	}
+
+	line, column := lineColumn(layout.data, offset)
+	return line, column, true
+}
+
+// Check if the property has been modified after parsing.
+func (propertyLayout *propertyLayout) modified(property *Property) bool {
+	if property.Ident != propertyLayout.ident || len(property.Values) != len(propertyLayout.values) {
+		return true
+	}
+
+	for i, value := range property.Values {
+		if value != propertyLayout.values[i] {
+			return true
+		}
+	}
+
+	return false
+}
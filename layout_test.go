@@ -0,0 +1,110 @@
+package sgf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSgfWithLayoutRoundTrip(t *testing.T) {
+	var tests = []string{
+		"(;)",
+		"  (;FF[4] GM[1]\n\n ;B[aa]  ;W[bb]\t)\n",
+		"(;FF[4]C[soft\\\nbreak and \\:]AB[aa]\n   [bb]\r\n(;B[cc])\n(;B[dd]\n  ;W[ee] ) )\r\n(;FF[3])\n",
+		"(;LB[dd:a\\:b][ee:\\\\]AP[a\\:b:1])",
+		// ISO-8859-1, not valid UTF-8
+		"(;CA[ISO-8859-1]PB[J\xf6rg]C[\xe4\\]\xff] ;B[aa])",
+	}
+
+	for _, test := range tests {
+		collection, layout, err := ParseSgfWithLayout(test)
+		if err != nil {
+			t.Errorf("ParseSgfWithLayout(%q) returned error.", test)
+			continue
+		}
+
+		if sgf := collection.SgfWithLayout(DefaultSgfFormat, layout); sgf != test {
+			t.Errorf("SgfWithLayout(%q) mismatch. Got: %q.", test, sgf)
+		}
+	}
+}
+
+func TestParseSgfInvalidUTF8(t *testing.T) {
+	collection, err := ParseSgf("(;PB[J\xf6rg]LB[aa:\xe4\\:])")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	node := collection.GameTrees[0].Nodes[0]
+	if pb, lb := node.Property("PB").Values[0], node.Property("LB").Values[0]; pb != "J\xf6rg" || lb != "aa:\xe4\\:" {
+		t.Errorf("ParseSgf values mismatch. Got: %q %q.", pb, lb)
+	}
+}
+
+func TestSgfWithLayoutModified(t *testing.T) {
+	data := "(;FF[4]  C[a\\\nb]\n ;B[aa]\n ;W[bb] )\n"
+
+	collection, layout, err := ParseSgfWithLayout(data)
+	if err != nil {
+		t.Fatalf("ParseSgfWithLayout returned error.")
+	}
+
+	gameTree := collection.GameTrees[0]
+
+	// Modified property is formatted, the whitespace before it is kept
+	gameTree.Nodes[0].Property("C").Values[0] = "c]"
+	// New properties and nodes are formatted
+	gameTree.Nodes[1].NewProperty("C", "new")
+	gameTree.NewNode().NewProperty("B", "cc")
+	// Removed nodes are left out
+	gameTree.RemoveNodeAt(2)
+
+	wanted := "(;FF[4]  C[c\\]]\n ;B[aa]C[new]\n ;B[cc] )\n"
+	if sgf := collection.SgfWithLayout(DefaultSgfFormat, layout); sgf != wanted {
+		t.Errorf("SgfWithLayout() mismatch. wanted: %q, got: %q.", wanted, sgf)
+	}
+}
+
+func TestParseSgfWithLayoutErrors(t *testing.T) {
+	if _, _, err := ParseSgfWithLayout("(;"); err == nil {
+		t.Errorf("ParseSgfWithLayout did not return error.")
+	}
+
+	oldReadFileFunc := readFileFunc
+	defer func() {
+		readFileFunc = oldReadFileFunc
+	}()
+
+	readFileFunc = func(filename string) ([]byte, error) {
+		return nil, errors.New("")
+	}
+
+	if _, _, err := ParseSgfFileWithLayout("foo"); err == nil {
+		t.Errorf("ParseSgfFileWithLayout did not return error.")
+	}
+}
+
+func TestLayoutPosition(t *testing.T) {
+	collection, layout, err := ParseSgfWithLayout("(;FF[4]\r\n  GM[1];B[aa]\n(;W[bb]C[x\ny]\nN[z]))")
+	if err != nil {
+		t.Fatalf("ParseSgfWithLayout returned error.")
+	}
+
+	gameTree := collection.GameTrees[0]
+	variation := gameTree.GameTrees[0].Nodes[0]
+
+	if line, column, ok := layout.NodePosition(gameTree.Nodes[1]); !ok || line != 2 || column != 8 {
+		t.Errorf("NodePosition() mismatch. Got: %d:%d", line, column)
+	}
+
+	if line, column, ok := layout.PropertyPosition(gameTree.Nodes[0].Property("GM")); !ok || line != 2 || column != 3 {
+		t.Errorf("PropertyPosition(GM) mismatch. Got: %d:%d", line, column)
+	}
+
+	if line, column, ok := layout.PropertyPosition(variation.Property("N")); !ok || line != 5 || column != 1 {
+		t.Errorf("PropertyPosition(N) mismatch. Got: %d:%d", line, column)
+	}
+
+	if _, _, ok := layout.PropertyPosition(variation.NewProperty("C", "new")); ok {
+		t.Errorf("PropertyPosition() found a property that was not parsed.")
+	}
+}
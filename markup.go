@@ -0,0 +1,516 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Shape is a markup shape drawn on a point. FF[4] allows at most one shape per point.
+type Shape int
+
+const (
+	ShapeNone     Shape = iota
+	ShapeCircle         // CR
+	ShapeSquare         // SQ
+	ShapeTriangle       // TR
+	ShapeCross          // MA
+	ShapeSelected       // SL
+)
+
+// Property idents of the shapes, indexed by Shape.
+var shapeIdents = []string{"", "CR", "SQ", "TR", "MA", "SL"}
+
+// Returns the property ident used for the shape or an empty string for ShapeNone.
+func (shape Shape) Ident() string {
+	return shapeIdents[shape]
+}
+
+// Label is a text label (LB) placed on a point.
+type Label struct {
+	Point Point
+	Text  string
+}
+
+// Line is a pair of points used by arrows (AR) and lines (LN).
+type Line struct {
+	From Point
+	To   Point
+}
+
+//
+// Shapes
+//
+
+// Returns all the shapes marked on this Node. If the same point has multiple shapes, the last one wins; use
+// ValidateMarkup to detect such Nodes.
+func (node *Node) Shapes() (map[Point]Shape, error) {
+	shapes := map[Point]Shape{}
+
+	for shape := ShapeCircle; shape <= ShapeSelected; shape++ {
+		points, err := node.pointListValues(shape.Ident())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, point := range points {
+			shapes[point] = shape
+		}
+	}
+
+	return shapes, nil
+}
+
+// Returns the shape marked on the given point or ShapeNone if there is no shape.
+func (node *Node) Shape(point Point) Shape {
+	shapes, err := node.Shapes()
+	if err != nil {
+		return ShapeNone
+	}
+
+	return shapes[point]
+}
+
+// Marks the given point with the shape. Any other shape on the same point is removed. ShapeNone removes the
+// shape from the point.
+func (node *Node) SetShape(point Point, shape Shape) error {
+	for s := ShapeCircle; s <= ShapeSelected; s++ {
+		if err := node.removeListPoint(s.Ident(), point); err != nil {
+			return err
+		}
+	}
+
+	if shape != ShapeNone {
+		return node.addListPoint(shape.Ident(), point)
+	}
+
+	return nil
+}
+
+//
+// Labels
+//
+
+// Returns the labels (LB) of this Node.
+func (node *Node) Labels() ([]Label, error) {
+	labels := []Label{}
+
+	for _, property := range node.Properties {
+		if property.Ident != "LB" {
+			continue
+		}
+
+		for _, value := range property.Values {
+			first, text, ok := Compose(value).Split()
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Invalid label %q", value))
+			}
+
+			point, err := ParsePoint(first)
+			if err != nil {
+				return nil, err
+			}
+
+			labels = append(labels, Label{point, text})
+		}
+	}
+
+	return labels, nil
+}
+
+// Sets the label text on the given point, replacing the existing label of the point.
+func (node *Node) SetLabel(point Point, text string) error {
+	if err := node.RemoveLabel(point); err != nil {
+		return err
+	}
+
+	node.appendValue("LB", string(NewCompose(point.String(), text)))
+	return nil
+}
+
+// Removes the label from the given point.
+func (node *Node) RemoveLabel(point Point) error {
+	labels, err := node.Labels()
+	if err != nil {
+		return err
+	}
+
+	values := []string{}
+	for _, label := range labels {
+		if label.Point != point {
+			values = append(values, string(NewCompose(label.Point.String(), label.Text)))
+		}
+	}
+
+	node.setValues("LB", values)
+	return nil
+}
+
+//
+// Arrows and lines
+//
+
+// Returns the arrows (AR) of this Node.
+func (node *Node) Arrows() ([]Line, error) {
+	return node.lineValues("AR")
+}
+
+// Adds an arrow from a point to another. Adding an existing arrow does nothing.
+func (node *Node) AddArrow(from, to Point) error {
+	return node.addLine("AR", Line{from, to})
+}
+
+// Removes the arrow between the given points.
+func (node *Node) RemoveArrow(from, to Point) error {
+	return node.removeLine("AR", Line{from, to})
+}
+
+// Returns the lines (LN) of this Node.
+func (node *Node) Lines() ([]Line, error) {
+	return node.lineValues("LN")
+}
+
+// Adds a line between the given points. Adding an existing line does nothing.
+func (node *Node) AddLine(from, to Point) error {
+	return node.addLine("LN", Line{from, to})
+}
+
+// Removes the line between the given points.
+func (node *Node) RemoveLine(from, to Point) error {
+	return node.removeLine("LN", Line{from, to})
+}
+
+//
+// Dimmed points and view
+//
+
+// Returns the points dimmed (DD) by this Node. The second return value tells whether the Node has DD property at
+// all. DD[] clears the dimmed points, in which case an empty slice and true are returned. Use InheritedDimmed to get
+// the dimmed points in effect at a Node.
+func (node *Node) Dimmed() ([]Point, bool, error) {
+	return node.inheritableValues("DD")
+}
+
+// Sets the dimmed points (DD) of this Node. Calling without points clears the dimmed points inherited from the
+// previous Nodes.
+func (node *Node) SetDimmed(points ...Point) {
+	node.setInheritableValues("DD", points)
+}
+
+// Returns the points of the visible area (VW) set by this Node. The second return value tells whether the Node has
+// VW property at all. VW[] resets the view to the whole board, in which case an empty slice and true are returned.
+// Use InheritedView to get the view in effect at a Node.
+func (node *Node) View() ([]Point, bool, error) {
+	return node.inheritableValues("VW")
+}
+
+// Sets the visible area (VW) of this Node. Calling without points resets the view to the whole board.
+func (node *Node) SetView(points ...Point) {
+	node.setInheritableValues("VW", points)
+}
+
+// Returns the dimmed points in effect at the last Node of the given path (see GameTree.PathTo). DD stays in effect
+// until it is set again, so the last Node with DD property decides the points.
+func InheritedDimmed(path []*Node) ([]Point, error) {
+	return inheritedValues(path, "DD")
+}
+
+// Returns the visible area in effect at the last Node of the given path (see GameTree.PathTo). An empty slice means
+// the whole board is visible.
+func InheritedView(path []*Node) ([]Point, error) {
+	return inheritedValues(path, "VW")
+}
+
+// Returns the upper left and the lower right corner of the rectangle containing the visible area in effect at the
+// last Node of the path. The corners of the whole board are returned if the whole board is visible.
+func ViewRectangle(path []*Node, width, height int) (Point, Point, error) {
+	view, err := InheritedView(path)
+	if err != nil {
+		return Point{}, Point{}, err
+	}
+
+	if len(view) == 0 {
+		return Point{0, 0}, Point{width - 1, height - 1}, nil
+	}
+
+	topLeft, bottomRight := view[0], view[0]
+	for _, point := range view[1:] {
+		if point.X < topLeft.X {
+			topLeft.X = point.X
+		}
+		if point.Y < topLeft.Y {
+			topLeft.Y = point.Y
+		}
+		if point.X > bottomRight.X {
+			bottomRight.X = point.X
+		}
+		if point.Y > bottomRight.Y {
+			bottomRight.Y = point.Y
+		}
+	}
+
+	if !topLeft.OnBoard(width, height) || !bottomRight.OnBoard(width, height) {
+		return Point{}, Point{}, errors.New("View is not on the board")
+	}
+
+	return topLeft, bottomRight, nil
+}
+
+func inheritedValues(path []*Node, ident string) ([]Point, error) {
+	for i := len(path) - 1; i >= 0; i-- {
+		points, ok, err := path[i].inheritableValues(ident)
+		if err != nil || ok {
+			return points, err
+		}
+	}
+
+	return []Point{}, nil
+}
+
+//
+// Validation
+//
+
+// Checks the markup of this Node. Markup is not valid if:
+//   - Any markup property contains an invalid point.
+//   - A point has more than one of CR, SQ, TR, MA and SL.
+//   - A point has more than one label.
+//   - An arrow or a line starts and ends at the same point or is given more than once.
+func (node *Node) ValidateMarkup() error {
+	seen := map[Point]Shape{}
+
+	for shape := ShapeCircle; shape <= ShapeSelected; shape++ {
+		points, err := node.pointListValues(shape.Ident())
+		if err != nil {
+			return err
+		}
+
+		for _, point := range points {
+			if other, ok := seen[point]; ok {
+				return errors.New(fmt.Sprintf("Point %s has both %s and %s", point, other.Ident(), shape.Ident()))
+			}
+
+			seen[point] = shape
+		}
+	}
+
+	labels, err := node.Labels()
+	if err != nil {
+		return err
+	}
+
+	labeled := map[Point]bool{}
+	for _, label := range labels {
+		if labeled[label.Point] {
+			return errors.New(fmt.Sprintf("Point %s has more than one label", label.Point))
+		}
+
+		labeled[label.Point] = true
+	}
+
+	for _, ident := range []string{"AR", "LN"} {
+		lines, err := node.lineValues(ident)
+		if err != nil {
+			return err
+		}
+
+		seen := map[Line]bool{}
+		for _, line := range lines {
+			if line.From == line.To {
+				return errors.New(fmt.Sprintf("%s from %s to itself", ident, line.From))
+			}
+
+			// Lines do not have a direction
+			reverse := Line{line.To, line.From}
+			if seen[line] || (ident == "LN" && seen[reverse]) {
+				return errors.New(fmt.Sprintf("%s from %s to %s given more than once", ident, line.From, line.To))
+			}
+
+			seen[line] = true
+		}
+	}
+
+	return nil
+}
+
+//
+// Helpers
+//
+
+// Returns expanded points of all the properties with the given ident.
+func (node *Node) pointListValues(ident string) ([]Point, error) {
+	points := []Point{}
+
+	for _, property := range node.Properties {
+		if property.Ident != ident {
+			continue
+		}
+
+		p, err := ParsePointList(property.Values...)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, p...)
+	}
+
+	return points, nil
+}
+
+func (node *Node) addListPoint(ident string, point Point) error {
+	points, err := node.pointListValues(ident)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range points {
+		if p == point {
+			return nil
+		}
+	}
+
+	node.setValues(ident, pointValues(append(points, point)))
+	return nil
+}
+
+func (node *Node) removeListPoint(ident string, point Point) error {
+	points, err := node.pointListValues(ident)
+	if err != nil {
+		return err
+	}
+
+	kept := []Point{}
+	for _, p := range points {
+		if p != point {
+			kept = append(kept, p)
+		}
+	}
+
+	if len(kept) != len(points) {
+		node.setValues(ident, pointValues(kept))
+	}
+
+	return nil
+}
+
+func (node *Node) lineValues(ident string) ([]Line, error) {
+	lines := []Line{}
+
+	for _, property := range node.Properties {
+		if property.Ident != ident {
+			continue
+		}
+
+		for _, value := range property.Values {
+			first, second, ok := Compose(value).Split()
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Invalid %s value %q", ident, value))
+			}
+
+			from, err := ParsePoint(first)
+			if err != nil {
+				return nil, err
+			}
+
+			to, err := ParsePoint(second)
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, Line{from, to})
+		}
+	}
+
+	return lines, nil
+}
+
+func (node *Node) addLine(ident string, line Line) error {
+	lines, err := node.lineValues(ident)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lines {
+		if l == line {
+			return nil
+		}
+	}
+
+	node.appendValue(ident, string(NewCompose(line.From.String(), line.To.String())))
+	return nil
+}
+
+func (node *Node) removeLine(ident string, line Line) error {
+	lines, err := node.lineValues(ident)
+	if err != nil {
+		return err
+	}
+
+	values := []string{}
+	for _, l := range lines {
+		if l != line {
+			values = append(values, string(NewCompose(l.From.String(), l.To.String())))
+		}
+	}
+
+	node.setValues(ident, values)
+	return nil
+}
+
+func (node *Node) inheritableValues(ident string) ([]Point, bool, error) {
+	property := node.Property(ident)
+	if property == nil {
+		return nil, false, nil
+	}
+
+	points, err := node.pointListValues(ident)
+	return points, true, err
+}
+
+func (node *Node) setInheritableValues(ident string, points []Point) {
+	values := pointValues(points)
+	if len(values) == 0 {
+		// elist, clears the inherited value
+		values = []string{""}
+	}
+
+	node.setValues(ident, values)
+}
+
+// Appends the value to the first property with the given ident, creating the property if needed.
+func (node *Node) appendValue(ident, value string) {
+	if property := node.Property(ident); property != nil {
+		property.Values = append(property.Values, value)
+		return
+	}
+
+	node.NewProperty(ident, value)
+}
+
+// Replaces the values of all the properties with the given ident with a single property. The property is removed
+// if there are no values.
+func (node *Node) setValues(ident string, values []string) {
+	var property *Property
+
+	for i := 0; i < len(node.Properties); i++ {
+		if node.Properties[i].Ident != ident {
+			continue
+		}
+
+		if property == nil {
+			property = node.Properties[i]
+			continue
+		}
+
+		node.RemovePropertyAt(i)
+		i--
+	}
+
+	switch {
+	case len(values) == 0 && property != nil:
+		node.RemoveProperty(property)
+	case property != nil:
+		property.Values = values
+	case len(values) > 0:
+		node.NewProperty(ident, values...)
+	}
+}
@@ -0,0 +1,147 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestShapes(t *testing.T) {
+	_, _, n := NewCollection()
+	n.NewProperty("CR", "aa:bb")
+
+	if n.Shape(Point{1, 0}) != ShapeCircle {
+		t.Errorf("Shape() did not expand compressed point list.")
+	}
+
+	// Setting a new shape replaces the old one
+	if err := n.SetShape(Point{1, 0}, ShapeTriangle); err != nil {
+		t.Fatalf("SetShape() returned error.")
+	}
+
+	if n.Shape(Point{1, 0}) != ShapeTriangle {
+		t.Errorf("SetShape() did not set the shape.")
+	}
+
+	if len(n.Property("CR").Values) != 3 {
+		t.Errorf("SetShape() did not remove the old shape. CR: %v", n.Property("CR").Values)
+	}
+
+	if err := n.ValidateMarkup(); err != nil {
+		t.Errorf("ValidateMarkup() returned error: %s", err)
+	}
+
+	// Removing the last point removes the property
+	n.SetShape(Point{1, 0}, ShapeNone)
+
+	if n.Property("TR") != nil {
+		t.Errorf("SetShape(ShapeNone) did not remove the empty property.")
+	}
+}
+
+func TestLabels(t *testing.T) {
+	_, _, n := NewCollection()
+	n.SetLabel(Point{3, 3}, "A")
+	n.SetLabel(Point{4, 4}, "B")
+	n.SetLabel(Point{3, 3}, "C")
+
+	labels, err := n.Labels()
+	if err != nil {
+		t.Fatalf("Labels() returned error.")
+	}
+
+	if len(labels) != 2 || labels[0] != (Label{Point{4, 4}, "B"}) || labels[1] != (Label{Point{3, 3}, "C"}) {
+		t.Errorf("Labels() mismatch. Got: %v", labels)
+	}
+
+	n.RemoveLabel(Point{3, 3})
+	n.RemoveLabel(Point{4, 4})
+
+	if n.Property("LB") != nil {
+		t.Errorf("RemoveLabel() did not remove the empty property.")
+	}
+}
+
+func TestArrowsAndLines(t *testing.T) {
+	_, _, n := NewCollection()
+	n.AddArrow(Point{0, 0}, Point{2, 2})
+	n.AddArrow(Point{0, 0}, Point{2, 2})
+	n.AddLine(Point{1, 1}, Point{3, 3})
+
+	arrows, _ := n.Arrows()
+	if len(arrows) != 1 {
+		t.Errorf("AddArrow() added a duplicate arrow. Got: %v", arrows)
+	}
+
+	lines, _ := n.Lines()
+	if len(lines) != 1 || lines[0] != (Line{Point{1, 1}, Point{3, 3}}) {
+		t.Errorf("Lines() mismatch. Got: %v", lines)
+	}
+
+	n.RemoveArrow(Point{0, 0}, Point{2, 2})
+	if n.Property("AR") != nil {
+		t.Errorf("RemoveArrow() did not remove the empty property.")
+	}
+}
+
+func TestInheritedMarkup(t *testing.T) {
+	collection, err := ParseSgf("(;DD[aa:bb];;VW[cc](;DD[];VW[])(;B[dd]))")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error.")
+	}
+
+	gt := collection.GameTrees[0]
+
+	var tests = []struct {
+		node    *Node
+		dimmed  int
+		visible int
+	}{
+		{gt.Nodes[0], 4, 0},
+		{gt.Nodes[2], 4, 1},
+		{gt.GameTrees[0].Nodes[0], 0, 1},
+		{gt.GameTrees[0].Nodes[1], 0, 0},
+		{gt.GameTrees[1].Nodes[0], 4, 1},
+	}
+
+	for i, test := range tests {
+		path := gt.PathTo(test.node)
+
+		dimmed, err := InheritedDimmed(path)
+		if err != nil || len(dimmed) != test.dimmed {
+			t.Errorf("InheritedDimmed() mismatch in test %d. wanted: %d, got: %v.", i, test.dimmed, dimmed)
+		}
+
+		view, err := InheritedView(path)
+		if err != nil || len(view) != test.visible {
+			t.Errorf("InheritedView() mismatch in test %d. wanted: %d, got: %v.", i, test.visible, view)
+		}
+	}
+}
+
+func TestValidateMarkup(t *testing.T) {
+	var errTests = []string{
+		"(;CR[aa]SQ[aa])",
+		"(;TR[aa:bb]MA[bb])",
+		"(;LB[aa:1][aa:2])",
+		"(;AR[aa:aa])",
+		"(;AR[aa:bb][aa:bb])",
+		"(;LN[aa:bb][bb:aa])",
+		"(;SL[a])",
+	}
+
+	for _, test := range errTests {
+		collection, err := ParseSgf(test)
+		if err != nil {
+			t.Errorf("ParseSgf(%s) returned error.", test)
+			continue
+		}
+
+		if collection.GameTrees[0].Nodes[0].ValidateMarkup() == nil {
+			t.Errorf("ValidateMarkup(%s) did not return error.", test)
+		}
+	}
+
+	collection, _ := ParseSgf("(;CR[aa]SQ[bb]LB[aa:1][bb:2]AR[aa:bb][bb:aa]LN[aa:cc])")
+	if err := collection.GameTrees[0].Nodes[0].ValidateMarkup(); err != nil {
+		t.Errorf("ValidateMarkup() returned error: %s", err)
+	}
+}
@@ -0,0 +1,311 @@
+package sgf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeOptions control Merge3.
+type MergeOptions struct {
+	// Comments (C and GC) changed differently on both sides are joined instead of reported as conflicts
+	ConcatenateComments bool
+	CommentSeparator    string // separator of the joined comments
+}
+
+var (
+	// Default merge options.
+	DefaultMergeOptions = MergeOptions{CommentSeparator: "\n\n"}
+)
+
+// Conflict is a change made differently on both sides of Merge3.
+type Conflict struct {
+	Game int // index of the GameTree
+
+	// Path from the root Node of the GameTree in the merged collection to the conflicting Node, or to the parent Node
+	// of the conflicting variation. Nil for GameTrees.
+	Path []*Node
+
+	// Ident of the conflicting Property. Empty for a variation or a GameTree removed on one side and changed on the
+	// other.
+	Ident string
+
+	// Decoded values of the Property, nil if the Property is missing. For variations and GameTrees, the move of the
+	// first Node, nil on the side where the variation was removed.
+	Base, Ours, Theirs []string
+}
+
+// Returns the conflict in a human-readable form, e.g. `game 1, node 3 W[dd]: C: base "a", ours "b", theirs "c"`.
+func (conflict Conflict) String() string {
+	location := nodeLocation(conflict.Game, conflict.Path)
+
+	if conflict.Ident == "" {
+		name, removedBy, changedBy := "", "ours", "theirs"
+		if conflict.Ours != nil {
+			removedBy, changedBy = changedBy, removedBy
+		}
+
+		if conflict.Path != nil {
+			name = " variation " + strings.Join(conflict.Base, "")
+		}
+
+		return fmt.Sprintf("%s:%s removed by %s but changed by %s", location, name, removedBy, changedBy)
+	}
+
+	return fmt.Sprintf("%s: %s: base %s, ours %s, theirs %s", location, conflict.Ident, conflictValues(conflict.Base),
+		conflictValues(conflict.Ours), conflictValues(conflict.Theirs))
+}
+
+func conflictValues(values []string) string {
+	if values == nil {
+		return "none"
+	}
+
+	return quoteValues(values)
+}
+
+// Merges the changes made to the collection base in the collections ours and theirs. Returns the merged collection
+// and the conflicts found.
+//
+// GameTrees are merged by their index and the Nodes as trees, matching the variations by their move as in Diff.
+// Variations added on either side are kept; ours come first. Properties are merged per Node: a Property changed on
+// only one side takes the changed values. When a Property is changed differently on both sides, the comments are
+// joined if configured, and otherwise the values of ours are kept and a Conflict is reported. A variation removed on
+// one side is removed, unless it was changed on the other side, in which case it is kept and a Conflict is reported.
+func Merge3(base, ours, theirs *Collection, options MergeOptions) (*Collection, []Conflict) {
+	m := &merger{options: options, conflicts: []Conflict{}}
+	merged := &Collection{}
+
+	for i := 0; i < len(ours.GameTrees) || i < len(theirs.GameTrees); i++ {
+		game := mergeTriple{gameTreeRoot(base, i), gameTreeRoot(ours, i), gameTreeRoot(theirs, i)}
+
+		if !m.keep(i, nil, &game) {
+			continue
+		}
+
+		gameTree := &GameTree{}
+		m.merge(gameTree, i, nil, game)
+		merged.AddGameTree(gameTree)
+	}
+
+	return merged, m.conflicts
+}
+
+type merger struct {
+	options   MergeOptions
+	conflicts []Conflict
+}
+
+// Nodes of the base, ours and theirs at the same place of the tree, nil if missing.
+type mergeTriple struct {
+	base, ours, theirs *treeNode
+}
+
+// Merges the Nodes and their children to the end of the GameTree.
+func (m *merger) merge(gameTree *GameTree, game int, path []*Node, triple mergeTriple) {
+	node := gameTree.NewNode()
+	path = append(path[:len(path):len(path)], node)
+
+	m.mergeProperties(node, game, path, triple)
+
+	children := []mergeTriple{}
+	for _, child := range matchChildren(triple) {
+		if m.keep(game, path, &child) {
+			children = append(children, child)
+		}
+	}
+
+	if len(children) == 1 {
+		m.merge(gameTree, game, path, children[0])
+		return
+	}
+
+	for _, child := range children {
+		childGameTree := &GameTree{}
+		m.merge(childGameTree, game, path, child)
+		gameTree.AddGameTree(childGameTree)
+	}
+}
+
+// Returns whether the Node of the triple is kept. A Node removed on one side is kept only if it has been changed on
+// the other side, which is a conflict. The kept Node is then taken as it is on the other side.
+func (m *merger) keep(game int, path []*Node, triple *mergeTriple) bool {
+	if triple.ours != nil && triple.theirs != nil {
+		return true
+	}
+
+	if triple.base == nil {
+		return triple.ours != nil || triple.theirs != nil
+	}
+
+	other := triple.ours
+	if other == nil {
+		other = triple.theirs
+	}
+
+	if other == nil || len(diffNodes(nil, game, nil, *triple.base, *other)) == 0 {
+		return false
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{Game: game, Path: path, Base: tripleMove(triple.base),
+		Ours: tripleMove(triple.ours), Theirs: tripleMove(triple.theirs)})
+	triple.base = nil
+	return true
+}
+
+func (m *merger) mergeProperties(node *Node, game int, path []*Node, triple mergeTriple) {
+	base, ours, theirs := canonicalTreeNode(triple.base), canonicalTreeNode(triple.ours), canonicalTreeNode(triple.theirs)
+
+	// Properties of ours, followed by the properties only in theirs
+	idents := []string{}
+	for _, n := range []*Node{ours, theirs} {
+		for _, property := range n.Properties {
+			if ours.Property(property.Ident) == nil || n == ours {
+				idents = append(idents, property.Ident)
+			}
+		}
+	}
+
+	for _, ident := range idents {
+		baseValues, ourValues, theirValues := propertyValues(base, ident), propertyValues(ours, ident),
+			propertyValues(theirs, ident)
+
+		switch {
+		case equalPropertyValues(ourValues, theirValues), equalPropertyValues(theirValues, baseValues):
+			copyProperties(node, triple.ours, ident)
+		case equalPropertyValues(ourValues, baseValues):
+			copyProperties(node, triple.theirs, ident)
+		case m.options.ConcatenateComments && (ident == "C" || ident == "GC") && ourValues != nil && theirValues != nil:
+			node.NewProperty(ident, m.concatenate(rawValue(triple.base, ident), rawValue(triple.ours, ident),
+				rawValue(triple.theirs, ident)))
+		default:
+			copyProperties(node, triple.ours, ident)
+			m.conflicts = append(m.conflicts, Conflict{Game: game, Path: path, Ident: ident, Base: baseValues,
+				Ours: ourValues, Theirs: theirValues})
+		}
+	}
+}
+
+// Joins the comments. If both comments extend the comment of base, the base is included only once.
+func (m *merger) concatenate(base, ours, theirs string) string {
+	if base != "" && strings.HasPrefix(ours, base) && strings.HasPrefix(theirs, base) {
+		theirs = strings.TrimLeft(theirs[len(base):], " \t\r\n")
+	}
+
+	return ours + m.options.CommentSeparator + theirs
+}
+
+// Returns the children of the Nodes of the triple matched by their move, in the order of ours followed by the
+// children only in theirs.
+func matchChildren(triple mergeTriple) []mergeTriple {
+	base, ours, theirs := treeNodeChildren(triple.base), treeNodeChildren(triple.ours), treeNodeChildren(triple.theirs)
+	ourBase, theirBase := matchNodes(base, ours), matchNodes(base, theirs)
+	usedTheirs := make([]bool, len(theirs))
+
+	children := []mergeTriple{}
+	for j := range ours {
+		child := mergeTriple{ours: &ours[j]}
+
+		if ourBase[j] >= 0 {
+			child.base = &base[ourBase[j]]
+		}
+
+		for k := range theirs {
+			// Children added on both sides are matched by their move
+			sameBase := ourBase[j] >= 0 && theirBase[k] == ourBase[j]
+			bothAdded := ourBase[j] < 0 && theirBase[k] < 0 && nodeMove(theirs[k].node()) == nodeMove(ours[j].node())
+
+			if !usedTheirs[k] && (sameBase || bothAdded) {
+				usedTheirs[k] = true
+				child.theirs = &theirs[k]
+				break
+			}
+		}
+
+		children = append(children, child)
+	}
+
+	for k := range theirs {
+		if !usedTheirs[k] {
+			child := mergeTriple{theirs: &theirs[k]}
+			if theirBase[k] >= 0 {
+				child.base = &base[theirBase[k]]
+			}
+
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
+
+func gameTreeRoot(collection *Collection, i int) *treeNode {
+	if i >= len(collection.GameTrees) || len(collection.GameTrees[i].Nodes) == 0 {
+		return nil
+	}
+
+	return &treeNode{collection.GameTrees[i], 0}
+}
+
+func treeNodeChildren(n *treeNode) []treeNode {
+	if n == nil {
+		return nil
+	}
+
+	return n.children()
+}
+
+// Returns the canonical form of the Node, or an empty Node for nil.
+func canonicalTreeNode(n *treeNode) *Node {
+	if n == nil {
+		return &Node{}
+	}
+
+	return canonicalNode(n.node())
+}
+
+func tripleMove(n *treeNode) []string {
+	if n == nil {
+		return nil
+	}
+
+	return []string{variationName(n.node())}
+}
+
+func propertyValues(node *Node, ident string) []string {
+	if property := node.Property(ident); property != nil {
+		return property.Values
+	}
+
+	return nil
+}
+
+// Returns whether the values are equal, telling missing (nil) values apart from empty ones.
+func equalPropertyValues(a, b []string) bool {
+	return (a == nil) == (b == nil) && equalValues(a, b)
+}
+
+// Copies the properties with the ident from the Node to the other Node.
+func copyProperties(node *Node, from *treeNode, ident string) {
+	if from == nil {
+		return
+	}
+
+	for _, property := range from.node().Properties {
+		if property.Ident == ident {
+			node.NewProperty(ident, append([]string{}, property.Values...)...)
+		}
+	}
+}
+
+// Returns the first value of the property with the ident, as written in the SGF.
+func rawValue(n *treeNode, ident string) string {
+	if n == nil {
+		return ""
+	}
+
+	if property := n.node().Property(ident); property != nil && len(property.Values) > 0 {
+		return property.Values[0]
+	}
+
+	return ""
+}
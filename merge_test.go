@@ -0,0 +1,80 @@
+package sgf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	var tests = []struct {
+		base, ours, theirs string
+		concatenate        bool
+		merged             string
+		conflicts          []string
+	}{
+		// Changes on different sides
+		{"(;PB[a];B[pd];W[dd])", "(;PB[b];B[pd];W[dd])", "(;PB[a];B[pd];W[dd]C[x])", false,
+			"(;PB[b];B[pd];W[dd]C[x])", []string{}},
+		// Same change on both sides, formatted differently
+		{"(;AB[aa])", "(;AB[aa][ab]PL[W])", "(;AB[aa:ab]\n;W[cc])", false, "(;AB[aa][ab]PL[W];W[cc])", []string{}},
+		// Variations added on both sides
+		{"(;GM[1];B[pd])", "(;GM[1];B[pd](;W[dd])(;W[dp]))", "(;GM[1];B[pd](;W[pp];B[dd])(;W[dp]C[x]))", false,
+			"(;GM[1];B[pd](;W[dd])(;W[dp]C[x])(;W[pp];B[dd]))", []string{}},
+		// Variation removed on one side
+		{"(;GM[1](;B[pd])(;B[dd]))", "(;GM[1];B[pd])", "(;GM[1](;B[pd]C[x])(;B[dd]))", false,
+			"(;GM[1];B[pd]C[x])", []string{}},
+		// Variation removed on one side and changed on the other
+		{"(;GM[1](;B[pd])(;B[dd];W[aa]))", "(;GM[1];B[pd])", "(;GM[1](;B[pd])(;B[dd];W[aa]C[x]))", false,
+			"(;GM[1](;B[pd])(;B[dd];W[aa]C[x]))",
+			[]string{"game 1, node 0: variation B[dd] removed by ours but changed by theirs"}},
+		// Conflicting properties
+		{"(;PB[a]KM[6.5]C[x];B[aa])", "(;PB[b]KM[7.5]C[x y];B[aa])", "(;PB[c]C[x z];B[aa])", false,
+			"(;PB[b]KM[7.5]C[x y];B[aa])", []string{
+				`game 1, node 0: PB: base "a", ours "b", theirs "c"`,
+				`game 1, node 0: KM: base "6.5", ours "7.5", theirs none`,
+				`game 1, node 0: C: base "x", ours "x y", theirs "x z"`,
+			}},
+		// Concatenated comments
+		{"(;C[x];B[aa]C[a])", "(;C[x y];B[aa]C[b])", "(;C[x\nz];B[aa]C[c];W[bb]C[d])", true,
+			"(;C[x y\n\nz];B[aa]C[b\n\nc];W[bb]C[d])", []string{}},
+		// GameTrees
+		{"(;GN[a])(;GN[b])", "(;GN[a])", "(;GN[a])(;GN[c])", false, "(;GN[a])(;GN[c])",
+			[]string{`game 2: removed by ours but changed by theirs`}},
+		{"(;GN[a])", "(;GN[a])(;GN[b])", "(;GN[a])(;GN[c])", false, "(;GN[a])(;GN[b])",
+			[]string{`game 2, node 0: GN: base none, ours "b", theirs "c"`}},
+	}
+
+	for _, test := range tests {
+		base, _ := ParseSgf(test.base)
+		ours, _ := ParseSgf(test.ours)
+		theirs, _ := ParseSgf(test.theirs)
+
+		options := DefaultMergeOptions
+		options.ConcatenateComments = test.concatenate
+
+		merged, conflicts := Merge3(base, ours, theirs, options)
+
+		if s := merged.Sgf(SgfFormat{}); s != test.merged {
+			t.Errorf("Merge3(%s, %s, %s) mismatch.\nWanted: %s\ngot:    %s", test.base, test.ours, test.theirs, test.merged, s)
+		}
+
+		messages := []string{}
+		for _, conflict := range conflicts {
+			messages = append(messages, conflict.String())
+		}
+
+		if !reflect.DeepEqual(messages, test.conflicts) {
+			t.Errorf("Merge3(%s, %s, %s) conflicts mismatch.\nWanted: %q\ngot:    %q", test.base, test.ours, test.theirs,
+				test.conflicts, messages)
+		}
+	}
+}
+
+func TestMerge3Unchanged(t *testing.T) {
+	collection, _ := ParseSgf("(;GM[1]FF[4]SZ[19];B[pd](;W[dd];B[pp])(;W[dp]LB[aa:A]))")
+
+	merged, conflicts := Merge3(collection, collection, collection, DefaultMergeOptions)
+	if len(conflicts) != 0 || len(Diff(collection, merged)) != 0 || merged.Hash() != collection.Hash() {
+		t.Errorf("Merge3() of the same collections mismatch. Got: %s", merged.Sgf(SgfFormat{}))
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Package ngf converts WBaduk/Cyberoro NGF game records to sgf Collections.
+
+NGF file contains game information on the first twelve lines followed by a line per move:
+
+	Jubango Game 1                  event
+	19                              board size
+	Gu Li 9P                        white player and rank
+	Lee Sedol 9P                    black player and rank
+	www.cyberoro.com                source
+	0                               handicap
+	0
+	7.5                             komi
+	20140126 [13:05]                date
+	5
+	White wins by resignation!      result
+	3                               number of moves
+	PMABBQEQE                       moves
+	PMACWEQEQ
+	PMADBRQRQ
+
+Move lines contain the move number, color and the coordinates, followed by the same coordinates repeated. The
+repeated pair is ignored. Coordinates are letters starting from 'B' which is the first line of the board, "AA" is a
+pass.
+*/
+package ngf
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+// Line numbers of the game information.
+const (
+	lineEvent = iota
+	lineSize
+	lineWhite
+	lineBlack
+	lineSource
+	lineHandicap
+	_
+	lineKomi
+	lineDate
+	_
+	lineResult
+	lineMoveCount
+	lineMoves
+)
+
+var readFileFunc func(string) ([]byte, error) = ioutil.ReadFile
+
+// Parse given filename as a NGF file.
+func ParseFile(filename string) (*sgf.Collection, error) {
+	bytes, err := readFileFunc(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(string(bytes))
+}
+
+// Parse given data as a NGF file. The returned collection contains a single GameTree.
+func Parse(data string) (*sgf.Collection, error) {
+	lines := strings.Split(strings.Replace(data, "\r", "", -1), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	if len(lines) < lineMoves {
+		return nil, errors.New("Missing game information")
+	}
+
+	size, err := strconv.Atoi(lines[lineSize])
+	if err != nil || size < 2 || size > 25 {
+		return nil, createError("Invalid board size", lineSize)
+	}
+
+	collection, gameTree, root := sgf.NewCollection()
+	root.NewProperty("FF", "4")
+	root.NewProperty("GM", "1")
+	root.NewProperty("SZ", strconv.Itoa(size))
+
+	addGameInfo(root, lines)
+
+	handicap, err := strconv.Atoi(lines[lineHandicap])
+	if err != nil {
+		return nil, createError("Invalid handicap", lineHandicap)
+	}
+
+	if handicap >= 2 {
+		points := sgf.HandicapPoints(size, handicap)
+		if points == nil {
+			return nil, createError(fmt.Sprintf("Invalid handicap %d", handicap), lineHandicap)
+		}
+
+		root.NewProperty("HA", strconv.Itoa(handicap))
+		root.NewProperty("AB", sgf.CompressPoints(points)...)
+	}
+
+	for i := lineMoves; i < len(lines); i++ {
+		line := lines[i]
+		if !strings.HasPrefix(line, "PM") {
+			continue
+		}
+
+		if len(line) < 7 {
+			return nil, createError("Invalid move", i)
+		}
+
+		color := line[4:5]
+		if color != "B" && color != "W" {
+			return nil, createError(fmt.Sprintf("Invalid color %s", color), i)
+		}
+
+		// 'A' is used for passes
+		x, y := int(line[5])-'B', int(line[6])-'B'
+		if x == -1 && y == -1 {
+			gameTree.NewNode().NewProperty(color, "")
+			continue
+		}
+
+		point := sgf.Point{X: x, Y: y}
+		if !point.OnBoard(size, size) {
+			return nil, createError("Invalid move", i)
+		}
+
+		gameTree.NewNode().NewProperty(color, point.String())
+	}
+
+	return collection, nil
+}
+
+func addGameInfo(root *sgf.Node, lines []string) {
+	if event := lines[lineEvent]; event != "" {
+		root.NewProperty("EV", event)
+	}
+
+	if name, rank := splitPlayer(lines[lineBlack]); name != "" {
+		root.NewProperty("PB", name)
+		if rank != "" {
+			root.NewProperty("BR", rank)
+		}
+	}
+
+	if name, rank := splitPlayer(lines[lineWhite]); name != "" {
+		root.NewProperty("PW", name)
+		if rank != "" {
+			root.NewProperty("WR", rank)
+		}
+	}
+
+	if source := lines[lineSource]; source != "" {
+		root.NewProperty("SO", source)
+	}
+
+	if komi, err := strconv.ParseFloat(lines[lineKomi], 64); err == nil {
+		root.NewProperty("KM", strconv.FormatFloat(komi, 'f', -1, 64))
+	}
+
+	if date := parseDate(lines[lineDate]); date != "" {
+		root.NewProperty("DT", date)
+	}
+
+	if result := parseResult(lines[lineResult]); result != "" {
+		root.NewProperty("RE", result)
+	}
+}
+
+var playerRegexp = regexp.MustCompile(`^(.*?)\s+(\d+[PpDdKk])$`)
+
+// Splits "Lee Sedol 9P" to name and rank.
+func splitPlayer(player string) (string, string) {
+	if m := playerRegexp.FindStringSubmatch(player); m != nil {
+		return m[1], strings.ToLower(m[2])
+	}
+
+	return player, ""
+}
+
+var dateRegexp = regexp.MustCompile(`^(\d{4})-?(\d{2})-?(\d{2})`)
+
+// Converts dates like "20140126 [13:05]" to "2014-01-26".
+func parseDate(date string) string {
+	if m := dateRegexp.FindStringSubmatch(date); m != nil {
+		return fmt.Sprintf("%s-%s-%s", m[1], m[2], m[3])
+	}
+
+	return ""
+}
+
+var scoreRegexp = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// Converts results like "White wins by resignation!" or "Black wins by 3.5 points" to RE values. Korean results are
+// recognized only when the data has been converted from EUC-KR to UTF-8.
+func parseResult(result string) string {
+	lower := strings.ToLower(result)
+
+	var winner string
+	switch {
+	case strings.Contains(lower, "draw") || strings.Contains(lower, "jigo"):
+		return "0"
+	case strings.HasPrefix(lower, "white") || strings.Contains(lower, "백"):
+		winner = "W"
+	case strings.HasPrefix(lower, "black") || strings.Contains(lower, "흑"):
+		winner = "B"
+	default:
+		return ""
+	}
+
+	switch {
+	case strings.Contains(lower, "resign") || strings.Contains(lower, "불계"):
+		return winner + "+R"
+	case strings.Contains(lower, "time") || strings.Contains(lower, "시간"):
+		return winner + "+T"
+	}
+
+	if score := scoreRegexp.FindString(lower); score != "" {
+		return winner + "+" + score
+	}
+
+	return winner + "+"
+}
+
+func createError(msg string, line int) error {
+	return errors.New(fmt.Sprintf("%s [line %d]", msg, line+1))
+}
@@ -0,0 +1,92 @@
+package ngf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+func TestParseFile(t *testing.T) {
+	var tests = []struct {
+		filename string
+		wanted   string
+	}{
+		{"testdata/jubango.ngf",
+			"(;FF[4]GM[1]SZ[19]EV[Jubango Game 1]PB[Lee Sedol]BR[9p]PW[Gu Li]WR[9p]SO[www.cyberoro.com]KM[7.5]" +
+				"DT[2014-01-26]RE[W+R];B[pd];W[dp];B[qp];W[])"},
+		{"testdata/handicap.ngf",
+			"(;FF[4]GM[1]SZ[19]EV[Teaching game]PB[Student]PW[Teacher]WR[5d]SO[www.wbaduk.com]KM[0.5]" +
+				"DT[2015-06-01]RE[B+3.5]HA[4]AB[dd][pd][dp][pp];W[qj];B[pj])"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseFile(test.filename)
+		if err != nil {
+			t.Errorf("ParseFile(%s) returned error: %s", test.filename, err)
+			continue
+		}
+
+		if sgf := collection.Sgf(sgf.NoNewLinesSgfFormat); sgf != test.wanted {
+			t.Errorf("ParseFile(%s) mismatch. Got: %s.", test.filename, sgf)
+		}
+	}
+}
+
+func TestReadFileError(t *testing.T) {
+	oldReadFileFunc := readFileFunc
+	defer func() {
+		readFileFunc = oldReadFileFunc
+	}()
+
+	readFileFunc = func(filename string) ([]byte, error) {
+		return nil, errors.New("")
+	}
+
+	if _, err := ParseFile("foo"); err == nil {
+		t.Errorf("ParseFile did not return error.")
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	var tests = []struct {
+		result string
+		wanted string
+	}{
+		{"White wins by resignation!", "W+R"},
+		{"Black wins by 3.5 points", "B+3.5"},
+		{"White wins on time", "W+T"},
+		{"백 불계승", "W+R"},
+		{"흑 6.5집승", "B+6.5"},
+		{"Draw", "0"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if result := parseResult(test.result); result != test.wanted {
+			t.Errorf("parseResult(%s) mismatch. wanted: %s, got: %s.", test.result, test.wanted, result)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	header := func(size, handicap string) string {
+		return "Event\n" + size + "\nW\nB\nsource\n" + handicap + "\n0\n6.5\n20140126\n0\n\n1\n"
+	}
+
+	var errTests = []string{
+		"",
+		header("abc", "0"),
+		header("19", "abc"),
+		header("19", "12"),
+		header("19", "0") + "PMAB",
+		header("19", "0") + "PMABXQE",
+		header("9", "0") + "PMABBQE",
+	}
+
+	for _, test := range errTests {
+		if _, err := Parse(test); err == nil {
+			t.Errorf("Parse(%q) did not return error.", test)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// MergeGamesOptions control MergeGames.
+type MergeGamesOptions struct {
+	Moves         int    // number of moves merged from each game, 0 for all the moves of the main line
+	CountProperty string // private property where the number of games through a Node is written, empty for none
+	CountComment  bool   // write the number of games through a Node to its comment (C)
+	Symmetry      bool   // rotate and mirror the games so that the same openings played in other corners are merged
+}
+
+var (
+	// Default options of MergeGames.
+	DefaultMergeGamesOptions = MergeGamesOptions{CountProperty: "GAMES"}
+)
+
+// Merges the main lines of the games into a single tree of variations, e.g. to build an opening tree. Games having
+// the same moves share the same Nodes. Only the moves (B and W) are merged; passes are written as empty values.
+// Variations are ordered by the number of games, so the main line follows the most played moves.
+//
+// The root Node of the returned GameTree has the board size of the games, which must all have the same size. The
+// number of games going through each Node is recorded as configured in the options.
+func MergeGames(games []*GameTree, options MergeGamesOptions) (*GameTree, error) {
+	tree := &GameTree{}
+	root := tree.NewNode()
+	counts := map[*Node]int{root: len(games)}
+	width, height := 19, 19
+
+	for i, game := range games {
+		if len(game.Nodes) == 0 {
+			return nil, errors.New(fmt.Sprintf("Game %d is empty", i+1))
+		}
+
+		w, h, err := game.Nodes[0].BoardSize()
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Game %d: %s", i+1, err))
+		}
+
+		if i == 0 {
+			width, height = w, h
+		} else if w != width || h != height {
+			return nil, errors.New(fmt.Sprintf("Game %d: board size %dx%d differs from %dx%d", i+1, w, h, width, height))
+		}
+
+		moves, err := openingMoves(game, width, height, options)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Game %d: %s", i+1, err))
+		}
+
+		position := treeNode{tree, 0}
+		for _, move := range moves {
+			position = addMove(position, move)
+			counts[position.node()]++
+		}
+	}
+
+	if width == height {
+		root.NewProperty("SZ", strconv.Itoa(width))
+	} else {
+		root.NewProperty("SZ", string(NewCompose(strconv.Itoa(width), strconv.Itoa(height))))
+	}
+
+	sortVariations(tree, counts)
+
+	for node, count := range counts {
+		if options.CountProperty != "" {
+			node.NewProperty(options.CountProperty, strconv.Itoa(count))
+		}
+
+		if options.CountComment {
+			comment := fmt.Sprintf("%d games", count)
+			if count == 1 {
+				comment = "1 game"
+			}
+
+			node.NewProperty("C", comment)
+		}
+	}
+
+	return tree, nil
+}
+
+// Move of a game, as a B or W property.
+type openingMove struct {
+	ident string
+	value string
+}
+
+// Returns the moves of the main line of the game, normalized by symmetry if configured.
+func openingMoves(game *GameTree, width, height int, options MergeGamesOptions) ([]openingMove, error) {
+	moves := []openingMove{}
+	points := []Point{}
+
+	for _, node := range game.MainLine() {
+		if options.Moves > 0 && len(moves) == options.Moves {
+			break
+		}
+
+		color, point, pass, err := node.Move(width, height)
+		if err != nil {
+			return nil, err
+		}
+
+		if color == Empty {
+			continue
+		}
+
+		move := openingMove{"B", ""}
+		if color == White {
+			move.ident = "W"
+		}
+
+		if !pass {
+			move.value = point.String()
+			points = append(points, point)
+		}
+
+		moves = append(moves, move)
+	}
+
+	if options.Symmetry {
+		symmetry := normalizingSymmetry(points, width, height)
+
+		for i, move := range moves {
+			if move.value != "" {
+				point, _ := ParsePoint(move.value)
+				moves[i].value = symmetry.Point(point, width, height).String()
+			}
+		}
+	}
+
+	return moves, nil
+}
+
+// Returns the child of the Node having the move, adding a new Node if there is no such child.
+func addMove(position treeNode, move openingMove) treeNode {
+	for _, child := range position.children() {
+		if nodeMove(child.node()) == fmt.Sprintf("%s[%s]", move.ident, move.value) {
+			return child
+		}
+	}
+
+	gameTree := position.gameTree
+
+	switch {
+	case position.index+1 < len(gameTree.Nodes):
+		// Move the following Nodes to a variation of their own
+		tail := &GameTree{Nodes: append([]*Node{}, gameTree.Nodes[position.index+1:]...), GameTrees: gameTree.GameTrees}
+		gameTree.Nodes = gameTree.Nodes[:position.index+1]
+		gameTree.GameTrees = nil
+		gameTree.AddGameTree(tail)
+		fallthrough
+	case len(gameTree.GameTrees) > 0:
+		variation, node := gameTree.NewGameTree()
+		node.NewProperty(move.ident, move.value)
+		return treeNode{variation, 0}
+	}
+
+	gameTree.NewNode().NewProperty(move.ident, move.value)
+	return treeNode{gameTree, len(gameTree.Nodes) - 1}
+}
+
+// Orders the variations by the number of games, the most played first.
+func sortVariations(gameTree *GameTree, counts map[*Node]int) {
+	sort.SliceStable(gameTree.GameTrees, func(i, j int) bool {
+		return counts[gameTree.GameTrees[i].Nodes[0]] > counts[gameTree.GameTrees[j].Nodes[0]]
+	})
+
+	for _, childGameTree := range gameTree.GameTrees {
+		sortVariations(childGameTree, counts)
+	}
+}
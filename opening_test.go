@@ -0,0 +1,59 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestMergeGames(t *testing.T) {
+	var tests = []struct {
+		games   string
+		options MergeGamesOptions
+		tree    string
+	}{
+		{"(;B[pd];W[dd];B[pq])(;B[pd];W[dp])(;B[pd];W[dd];B[qq])", DefaultMergeGamesOptions,
+			"(;SZ[19]GAMES[3];B[pd]GAMES[3](;W[dd]GAMES[2](;B[pq]GAMES[1])(;B[qq]GAMES[1]))(;W[dp]GAMES[1]))"},
+		{"(;B[pd];W[dp])(;B[pd];W[dd];B[pq])(;B[pd];W[dd];B[qq])", MergeGamesOptions{Moves: 2, CountComment: true},
+			"(;SZ[19]C[3 games];B[pd]C[3 games](;W[dd]C[2 games])(;W[dp]C[1 game]))"},
+		{"(;SZ[9];B[cc];W[];B[gg])(;SZ[9];B[cc];W[tt];B[gg])", MergeGamesOptions{},
+			"(;SZ[9];B[cc];W[];B[gg])"},
+		// Openings in different corners
+		{"(;B[pd];W[dp];B[pp])(;B[dd];W[pp];B[pd])(;B[dp];W[pd];B[dd])(;B[pd];W[dp];B[pq])",
+			MergeGamesOptions{Symmetry: true, CountProperty: "XG"},
+			"(;SZ[19]XG[4];B[pd]XG[4];W[dp]XG[4](;B[dd]XG[3])(;B[cd]XG[1]))"},
+		{"(;SZ[13:9];B[jc];W[dg])(;SZ[13:9];B[dg];W[jc])(;SZ[13:9];B[cd])", MergeGamesOptions{Symmetry: true},
+			"(;SZ[13:9](;B[jc];W[dg])(;B[kd]))"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.games)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.games, err)
+		}
+
+		tree, err := MergeGames(collection.GameTrees, test.options)
+		if err != nil {
+			t.Errorf("MergeGames(%s) returned error: %s", test.games, err)
+			continue
+		}
+
+		merged := &Collection{GameTrees: []*GameTree{tree}}
+		if s := merged.Sgf(SgfFormat{}); s != test.tree {
+			t.Errorf("MergeGames(%s) mismatch.\nWanted: %s\ngot:    %s", test.games, test.tree, s)
+		}
+	}
+}
+
+func TestMergeGamesErrors(t *testing.T) {
+	var tests = []string{
+		"(;SZ[19];B[aa])(;SZ[13];B[aa])",
+		"(;SZ[9];B[kk])",
+		"(;SZ[x])",
+	}
+
+	for _, test := range tests {
+		collection, _ := ParseSgf(test)
+		if _, err := MergeGames(collection.GameTrees, DefaultMergeGamesOptions); err == nil {
+			t.Errorf("MergeGames(%s) did not return error.", test)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package sgf
 
 import (
-	"errors"
 	"fmt"
 )
 
@@ -33,51 +32,71 @@ const (
 type lexeme struct {
 	tokenType tokenType
 	data      string
-	line      int
-	position  int
+	raw       string // property value as it was written, escaping included
+	space     string // whitespace preceding the token
+	offset    int    // number of characters before the first character of the token
+}
+
+// Error returned when the data is not valid SGF. Line and Column are counted from 1.
+type SyntaxError struct {
+	Message string
+	Offset  int // number of characters before the erroneous character
+	Line    int
+	Column  int
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("%s [line %d, column %d]", err.Message, err.Line, err.Column)
 }
 
 func lexicalAnalysis(data string) ([]lexeme, error) {
 	retval := []lexeme{}
 	curData := ""
+	space := ""
 	escapedText := false
-	line := 0
 	position := 0
+	start := 0      // position of the first character of curData
+	valueStart := 0 // byte offset of the property value in data
 
 	var lexerState = lexerStateOnlyControl
 
-	for _, c := range data {
+	for i, c := range data {
 		switch lexerState {
 		case lexerStateOnlyControl:
 			{
-				position++
-
 				// control chars
 				switch c {
 				case ' ', '\t', '\v', '\r', '\n':
 					if curData != "" {
-						return nil, createLexerError(fmt.Sprintf("Invalid character %c", c), line, position)
+						return nil, createLexerError(fmt.Sprintf("Invalid character %c", c), position)
 					}
 
-					if c == '\n' || c == '\r' {
-						line++
-					}
+					space += string(c)
 				case '(', ')', ';':
 					if curData != "" {
-						retval = append(retval, lexeme{tokenTypePropertyIdent, curData, line, position})
+						retval = append(retval, lexeme{tokenTypePropertyIdent, curData, curData, space, start})
+						space = ""
 					}
 
-					retval = append(retval, lexeme{runeToTokenType(c), "", line, position})
+					retval = append(retval, lexeme{runeToTokenType(c), "", "", space, position})
 					curData = ""
+					space = ""
 				case '[':
 					if curData != "" {
-						retval = append(retval, lexeme{runeToTokenType(c), curData, line, position})
+						retval = append(retval, lexeme{runeToTokenType(c), curData, curData, space, start})
+						space = ""
 					}
 					curData = ""
+					start = position
+					valueStart = i + 1
 					lexerState = lexerStatePropertyValue
 				default:
 					if c < 'A' || c > 'Z' {
-						return nil, createLexerError(fmt.Sprintf("Invalid character %c", c), line, position)
+						return nil, createLexerError(fmt.Sprintf("Invalid character %c", c), position)
+					}
+
+					if curData == "" {
+						start = position
 					}
 
 					curData += string(c)
@@ -85,36 +104,28 @@ func lexicalAnalysis(data string) ([]lexeme, error) {
 			}
 		case lexerStatePropertyValue:
 			{
-				position++
-				if c == '\\' {
-					if escapedText {
-						curData += string(c)
-						escapedText = false
-					} else {
-						escapedText = true
-					}
-				} else if c == ']' && !escapedText {
-					retval = append(retval, lexeme{tokenTypePropertyValue, curData, line, position})
+				if c == ']' && !escapedText {
+					// Raw value is taken from the data as it is, so that bytes that are not valid UTF-8 are kept.
+					// Escaping and soft linebreaks are removed from the value.
+					raw := data[valueStart:i]
+					retval = append(retval, lexeme{tokenTypePropertyValue, unescapeValue(raw), raw, space, start})
 
 					lexerState = lexerStateOnlyControl
-					curData = ""
-					escapedText = false
+					space = ""
 				} else {
-					if !escapedText || (c != '\n' && c != '\r') {
-						curData += string(c)
-					}
-					escapedText = false
+					escapedText = c == '\\' && !escapedText
 				}
 			}
 		}
+
+		position++
 	}
 
-	if curData != "" {
-		if lexerState == lexerStateOnlyControl {
-			retval = append(retval, lexeme{tokenTypePropertyIdent, curData, line, position})
-		} else {
-			return nil, createLexerError("value left open", line, position)
-		}
+	switch {
+	case lexerState == lexerStatePropertyValue && valueStart < len(data):
+		return nil, createLexerError("value left open", start)
+	case lexerState == lexerStateOnlyControl && curData != "":
+		retval = append(retval, lexeme{tokenTypePropertyIdent, curData, curData, space, start})
 	}
 
 	return retval, nil
@@ -135,7 +146,8 @@ func runeToTokenType(r rune) tokenType {
 	}
 }
 
-func parse(lexemes []lexeme) (*Collection, error) {
+// Parses the lexemes to a collection. Original formatting is recorded to the layout unless it is nil.
+func parse(lexemes []lexeme, layout *Layout) (*Collection, error) {
 	c := Collection{}
 	gameTreeStack := make([]*GameTree, 0)
 	var curGameTree *GameTree
@@ -159,6 +171,7 @@ func parse(lexemes []lexeme) (*Collection, error) {
 
 			// Create a new game tree
 			curGameTree = &GameTree{}
+			layout.addGameTree(curGameTree, l)
 			// Add game tree to collection
 			c.GameTrees = append(c.GameTrees, curGameTree)
 			// Change state
@@ -173,6 +186,7 @@ func parse(lexemes []lexeme) (*Collection, error) {
 			}
 
 			curNode = &Node{}
+			layout.addNode(curNode, l)
 			curGameTree.Nodes = append(curGameTree.Nodes, curNode)
 			state = parserStateNode
 		// Parsing of a node.
@@ -191,6 +205,7 @@ func parse(lexemes []lexeme) (*Collection, error) {
 			case tokenTypePropertyIdent:
 				// New property starts
 				curProperty = &Property{Ident: l.data}
+				layout.addProperty(curProperty, l)
 				curNode.Properties = append(curNode.Properties, curProperty)
 
 				// Next must come the value
@@ -202,10 +217,12 @@ func parse(lexemes []lexeme) (*Collection, error) {
 				}
 
 				// An extra value to current property
-				curProperty.Values = append(curProperty.Values, l.data)
+				curProperty.Values = append(curProperty.Values, propertyValue(curProperty, l))
+				layout.addValue(curProperty, l)
 			// New node starts after current node
 			case tokenTypeNode:
 				curNode = &Node{}
+				layout.addNode(curNode, l)
 				curGameTree.Nodes = append(curGameTree.Nodes, curNode)
 				curProperty = nil
 			// New game tree starts
@@ -216,6 +233,7 @@ func parse(lexemes []lexeme) (*Collection, error) {
 
 				// create new game tree
 				newGameTree := &GameTree{}
+				layout.addGameTree(newGameTree, l)
 				// Append game tree to a current game tree as a child
 				curGameTree.GameTrees = append(curGameTree.GameTrees, newGameTree)
 				// Add current game tree to stack
@@ -230,6 +248,7 @@ func parse(lexemes []lexeme) (*Collection, error) {
 				// Clean up node related state
 				curProperty = nil
 				curNode = nil
+				layout.endGameTree(curGameTree, l)
 
 				// if stack is empty go to a collection state (whole new game tree must be started)
 				if len(gameTreeStack) == 0 {
@@ -249,7 +268,8 @@ func parse(lexemes []lexeme) (*Collection, error) {
 			}
 
 			// Add value to current property
-			curProperty.Values = append(curProperty.Values, l.data)
+			curProperty.Values = append(curProperty.Values, propertyValue(curProperty, l))
+			layout.addValue(curProperty, l)
 			state = parserStateNode
 		}
 	}
@@ -261,10 +281,56 @@ func parse(lexemes []lexeme) (*Collection, error) {
 	return &c, nil
 }
 
-func createLexerError(msg string, line, position int) error {
-	return errors.New(fmt.Sprintf("%s [line %d, position %d]", msg, line, position))
+// Returns the value of the property value lexeme. Values of composed properties keep "\:" and "\\" escaped so that
+// Compose.Split can tell escaped colons from the separator.
+func propertyValue(property *Property, l lexeme) string {
+	if property.Info().Composed != ValueUnknown {
+		return unescapeComposedValue(l.raw)
+	}
+
+	return l.data
+}
+
+func createLexerError(msg string, offset int) error {
+	return &SyntaxError{Message: msg, Offset: offset}
 }
 
 func createParserError(msg string, lexeme lexeme) error {
-	return errors.New(fmt.Sprintf("%s [line %d, position %d]", msg, lexeme.line, lexeme.position))
+	return &SyntaxError{Message: msg, Offset: lexeme.offset}
+}
+
+// Fills in the line and the column of a syntax error found in the data.
+func locateSyntaxError(err error, data string) error {
+	if syntaxError, ok := err.(*SyntaxError); ok {
+		syntaxError.Line, syntaxError.Column = lineColumn(data, syntaxError.Offset)
+	}
+
+	return err
+}
+
+// Returns the line and the column of the character at the offset, both counted from 1. "\r\n" is a single line break.
+func lineColumn(data string, offset int) (int, int) {
+	line, column := 1, 1
+	position := 0
+	previous := rune(0)
+
+	for _, c := range data {
+		if position == offset {
+			break
+		}
+
+		switch {
+		case c == '\n' && previous == '\r':
+		case c == '\n' || c == '\r':
+			line++
+			column = 1
+		default:
+			column++
+		}
+
+		previous = c
+		position++
+	}
+
+	return line, column
 }
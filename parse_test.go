@@ -5,11 +5,11 @@ import (
 )
 
 func ltype(tt tokenType) lexeme {
-	return lexeme{tt, "", 0, 0}
+	return lexeme{tt, "", "", "", 0}
 }
 
 func lvalue(tt tokenType, value string) lexeme {
-	return lexeme{tt, value, 0, 0}
+	return lexeme{tt, value, value, "", 0}
 }
 
 func TestLexicalAnalysis(t *testing.T) {
@@ -153,6 +153,34 @@ func TestLexicalAnalysisErrors(t *testing.T) {
 	}
 }
 
+func TestSyntaxErrorPosition(t *testing.T) {
+	var tests = []struct {
+		data   string
+		line   int
+		column int
+	}{
+		{"(;FF[4]\n;b[aa])", 2, 2},
+		{"(;FF[4]\r\n;B[aa]\r\n  C[a\nb]x)", 4, 3},
+		{"(;FF[4]\n\n\tff[1])", 3, 2},
+		{"(;FF[4]\n C[open", 2, 3},
+		{"\n\n  ;)", 3, 3},
+	}
+
+	for _, test := range tests {
+		_, err := ParseSgf(test.data)
+
+		syntaxError, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("ParseSgf(%q) did not return a syntax error. Got: %v", test.data, err)
+			continue
+		}
+
+		if syntaxError.Line != test.line || syntaxError.Column != test.column {
+			t.Errorf("ParseSgf(%q) error position mismatch. Wanted: %d:%d, got: %d:%d", test.data, test.line, test.column, syntaxError.Line, syntaxError.Column)
+		}
+	}
+}
+
 func TestParse(t *testing.T) {
 	var okTests = []struct {
 		data               string
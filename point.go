@@ -0,0 +1,185 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Point is a single point on the board. Coordinates start from zero and the origin is the upper left corner of the
+// board, so SGF point "aa" is Point{0, 0} and "sc" is Point{18, 2}.
+type Point struct {
+	X int
+	Y int
+}
+
+// Parse the given SGF point value. Lowercase letters 'a'-'z' map to coordinates 0-25 and uppercase letters 'A'-'Z'
+// to coordinates 26-51.
+func ParsePoint(value string) (Point, error) {
+	if len(value) != 2 {
+		return Point{}, errors.New(fmt.Sprintf("Invalid point %q", value))
+	}
+
+	x, ok := pointCoordinate(value[0])
+	if !ok {
+		return Point{}, errors.New(fmt.Sprintf("Invalid point %q", value))
+	}
+
+	y, ok := pointCoordinate(value[1])
+	if !ok {
+		return Point{}, errors.New(fmt.Sprintf("Invalid point %q", value))
+	}
+
+	return Point{x, y}, nil
+}
+
+// Parse the given point list values. Compressed point lists ("aa:cc") are expanded to all the points inside the
+// rectangle. Empty values are ignored so that elists (e.g. DD[]) return no points.
+func ParsePointList(values ...string) ([]Point, error) {
+	points := []Point{}
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		i := strings.IndexRune(value, ':')
+		if i < 0 {
+			point, err := ParsePoint(value)
+			if err != nil {
+				return nil, err
+			}
+
+			points = append(points, point)
+			continue
+		}
+
+		// Compressed point list, value contains the upper left and the lower right corners of the rectangle.
+		p1, err := ParsePoint(value[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		p2, err := ParsePoint(value[i+1:])
+		if err != nil {
+			return nil, err
+		}
+
+		if p1.X > p2.X || p1.Y > p2.Y {
+			return nil, errors.New(fmt.Sprintf("Invalid compressed point list %q", value))
+		}
+
+		for y := p1.Y; y <= p2.Y; y++ {
+			for x := p1.X; x <= p2.X; x++ {
+				points = append(points, Point{x, y})
+			}
+		}
+	}
+
+	return points, nil
+}
+
+// Compresses the points to point list values. Rectangles of points are written as compressed point lists ("aa:cc").
+// The result does not depend on the order of the points and duplicate points are ignored.
+func CompressPoints(points []Point) []string {
+	set := map[Point]bool{}
+	for _, point := range points {
+		set[point] = true
+	}
+
+	sorted := make([]Point, 0, len(set))
+	for point := range set {
+		sorted = append(sorted, point)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+
+		return sorted[i].X < sorted[j].X
+	})
+
+	values := []string{}
+	for _, start := range sorted {
+		if !set[start] {
+			continue
+		}
+
+		// Grow the rectangle first to the right and then down as long as all the points are available
+		end := start
+		for set[Point{end.X + 1, start.Y}] {
+			end.X++
+		}
+
+	rows:
+		for {
+			for x := start.X; x <= end.X; x++ {
+				if !set[Point{x, end.Y + 1}] {
+					break rows
+				}
+			}
+
+			end.Y++
+		}
+
+		for y := start.Y; y <= end.Y; y++ {
+			for x := start.X; x <= end.X; x++ {
+				delete(set, Point{x, y})
+			}
+		}
+
+		if start == end {
+			values = append(values, start.String())
+		} else {
+			values = append(values, start.String()+":"+end.String())
+		}
+	}
+
+	return values
+}
+
+// Returns the point in SGF format.
+func (point Point) String() string {
+	return string([]byte{coordinateByte(point.X), coordinateByte(point.Y)})
+}
+
+// Check if the point fits on a board of the given size.
+func (point Point) OnBoard(width, height int) bool {
+	return point.X >= 0 && point.Y >= 0 && point.X < width && point.Y < height
+}
+
+// Returns the values of the given points in SGF format.
+func pointValues(points []Point) []string {
+	values := make([]string, len(points))
+
+	for i, point := range points {
+		values[i] = point.String()
+	}
+
+	return values
+}
+
+func pointCoordinate(b byte) (int, bool) {
+	switch {
+	case b >= 'a' && b <= 'z':
+		return int(b - 'a'), true
+	case b >= 'A' && b <= 'Z':
+		return int(b-'A') + 26, true
+	}
+
+	return 0, false
+}
+
+func coordinateByte(c int) byte {
+	if c < 0 || c > 51 {
+		panic(fmt.Sprintf("coordinate out of range: %d", c))
+	}
+
+	if c < 26 {
+		return byte('a' + c)
+	}
+
+	return byte('A' + c - 26)
+}
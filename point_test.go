@@ -0,0 +1,97 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestParsePoint(t *testing.T) {
+	var okTests = []struct {
+		data   string
+		wanted Point
+	}{
+		{"aa", Point{0, 0}},
+		{"sc", Point{18, 2}},
+		{"zA", Point{25, 26}},
+		{"ZZ", Point{51, 51}},
+	}
+
+	for _, test := range okTests {
+		point, err := ParsePoint(test.data)
+		if err != nil {
+			t.Errorf("ParsePoint(%s) returned error.", test.data)
+			continue
+		}
+
+		if point != test.wanted {
+			t.Errorf("ParsePoint(%s) mismatch. wanted: %v, got: %v.", test.data, test.wanted, point)
+		}
+
+		if point.String() != test.data {
+			t.Errorf("Point.String() mismatch. wanted: %s, got: %s.", test.data, point.String())
+		}
+	}
+
+	for _, test := range []string{"", "a", "abc", "a1", "[a"} {
+		if _, err := ParsePoint(test); err == nil {
+			t.Errorf("ParsePoint(%s) did not return error.", test)
+		}
+	}
+}
+
+func TestParsePointList(t *testing.T) {
+	points, err := ParsePointList("aa", "bb:cc", "")
+	if err != nil {
+		t.Fatalf("ParsePointList returned error.")
+	}
+
+	wanted := []Point{{0, 0}, {1, 1}, {2, 1}, {1, 2}, {2, 2}}
+	if len(points) != len(wanted) {
+		t.Fatalf("ParsePointList length mismatch. wanted: %d, got: %d.", len(wanted), len(points))
+	}
+
+	for i, point := range points {
+		if point != wanted[i] {
+			t.Errorf("ParsePointList point mismatch at index %d. wanted: %v, got: %v.", i, wanted[i], point)
+		}
+	}
+
+	for _, test := range []string{"cc:bb", "aa:", "aa:bb:cc"} {
+		if _, err := ParsePointList(test); err == nil {
+			t.Errorf("ParsePointList(%s) did not return error.", test)
+		}
+	}
+}
+
+func TestCompressPoints(t *testing.T) {
+	var tests = []struct {
+		points []string
+		wanted []string
+	}{
+		{[]string{}, []string{}},
+		{[]string{"aa"}, []string{"aa"}},
+		{[]string{"bb", "aa", "ba", "ab"}, []string{"aa:bb"}},
+		{[]string{"aa:cc", "dd", "bb"}, []string{"aa:cc", "dd"}},
+		{[]string{"aa", "ba", "ca", "ab"}, []string{"aa:ca", "ab"}},
+	}
+
+	for _, test := range tests {
+		points, err := ParsePointList(test.points...)
+		if err != nil {
+			t.Errorf("ParsePointList(%v) returned error.", test.points)
+			continue
+		}
+
+		values := CompressPoints(points)
+		if len(values) != len(test.wanted) {
+			t.Errorf("CompressPoints(%v) mismatch. wanted: %v, got: %v.", test.points, test.wanted, values)
+			continue
+		}
+
+		for i, value := range values {
+			if value != test.wanted[i] {
+				t.Errorf("CompressPoints(%v) mismatch. wanted: %v, got: %v.", test.points, test.wanted, values)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package sgf
+
+// PropertyType tells in which kind of Nodes the property may appear (FF[4] property types).
+type PropertyType int
+
+const (
+	PropertyTypeNone     PropertyType = iota // may appear anywhere
+	PropertyTypeMove                         // only in nodes containing a move
+	PropertyTypeSetup                        // only in setup nodes
+	PropertyTypeRoot                         // only in root nodes
+	PropertyTypeGameInfo                     // only once per game, usually in the root node
+)
+
+// ValueType is the type of a property value (FF[4] value types).
+type ValueType int
+
+const (
+	ValueUnknown    ValueType = iota // private or otherwise unknown property
+	ValueNone                        // empty value
+	ValueNumber                      // [+|-]digits
+	ValueReal                        // number with an optional fraction
+	ValueDouble                      // 1 (normal) or 2 (emphasized)
+	ValueColor                       // B or W
+	ValueSimpleText                  // text without linebreaks
+	ValueText                        // formatted text
+	ValuePoint                       // point on the board
+	ValueMove                        // move on the board, empty or "tt" for pass
+	ValueStone                       // stone position on the board
+)
+
+// PropertyInfo describes a property and its values.
+type PropertyInfo struct {
+	Ident    string
+	Name     string
+	Type     PropertyType
+	Value    ValueType // type of the value or the first part of a composed value
+	Composed ValueType // type of the second part of a composed value ("a:b") or ValueUnknown if not composed
+	List     bool      // property may have multiple values
+	EList    bool      // property may have a single empty value
+	Inherit  bool      // value stays in effect in the following Nodes until it is set again
+}
+
+// Property registry, contains FF[4] properties and the Go (GM[1]) specific properties.
+var properties = map[string]PropertyInfo{}
+
+func init() {
+	for _, info := range []PropertyInfo{
+		// Move properties
+		{Ident: "B", Name: "Black", Type: PropertyTypeMove, Value: ValueMove},
+		{Ident: "KO", Name: "Ko", Type: PropertyTypeMove, Value: ValueNone},
+		{Ident: "MN", Name: "Set move number", Type: PropertyTypeMove, Value: ValueNumber},
+		{Ident: "W", Name: "White", Type: PropertyTypeMove, Value: ValueMove},
+
+		// Setup properties
+		{Ident: "AB", Name: "Add Black", Type: PropertyTypeSetup, Value: ValueStone, List: true},
+		{Ident: "AE", Name: "Add Empty", Type: PropertyTypeSetup, Value: ValuePoint, List: true},
+		{Ident: "AW", Name: "Add White", Type: PropertyTypeSetup, Value: ValueStone, List: true},
+		{Ident: "PL", Name: "Player to play", Type: PropertyTypeSetup, Value: ValueColor},
+
+		// Node annotation properties
+		{Ident: "C", Name: "Comment", Value: ValueText},
+		{Ident: "DM", Name: "Even position", Value: ValueDouble},
+		{Ident: "GB", Name: "Good for Black", Value: ValueDouble},
+		{Ident: "GW", Name: "Good for White", Value: ValueDouble},
+		{Ident: "HO", Name: "Hotspot", Value: ValueDouble},
+		{Ident: "N", Name: "Nodename", Value: ValueSimpleText},
+		{Ident: "UC", Name: "Unclear pos", Value: ValueDouble},
+		{Ident: "V", Name: "Value", Value: ValueReal},
+
+		// Move annotation properties
+		{Ident: "BM", Name: "Bad move", Type: PropertyTypeMove, Value: ValueDouble},
+		{Ident: "DO", Name: "Doubtful", Type: PropertyTypeMove, Value: ValueNone},
+		{Ident: "IT", Name: "Interesting", Type: PropertyTypeMove, Value: ValueNone},
+		{Ident: "TE", Name: "Tesuji", Type: PropertyTypeMove, Value: ValueDouble},
+
+		// Markup properties
+		{Ident: "AR", Name: "Arrow", Value: ValuePoint, Composed: ValuePoint, List: true},
+		{Ident: "CR", Name: "Circle", Value: ValuePoint, List: true},
+		{Ident: "DD", Name: "Dim points", Value: ValuePoint, List: true, EList: true, Inherit: true},
+		{Ident: "LB", Name: "Label", Value: ValuePoint, Composed: ValueSimpleText, List: true},
+		{Ident: "LN", Name: "Line", Value: ValuePoint, Composed: ValuePoint, List: true},
+		{Ident: "MA", Name: "Mark", Value: ValuePoint, List: true},
+		{Ident: "SL", Name: "Selected", Value: ValuePoint, List: true},
+		{Ident: "SQ", Name: "Square", Value: ValuePoint, List: true},
+		{Ident: "TR", Name: "Triangle", Value: ValuePoint, List: true},
+
+		// Root properties
+		{Ident: "AP", Name: "Application", Type: PropertyTypeRoot, Value: ValueSimpleText, Composed: ValueSimpleText},
+		{Ident: "CA", Name: "Charset", Type: PropertyTypeRoot, Value: ValueSimpleText},
+		{Ident: "FF", Name: "Fileformat", Type: PropertyTypeRoot, Value: ValueNumber},
+		{Ident: "GM", Name: "Game", Type: PropertyTypeRoot, Value: ValueNumber},
+		{Ident: "ST", Name: "Style", Type: PropertyTypeRoot, Value: ValueNumber},
+		{Ident: "SZ", Name: "Size", Type: PropertyTypeRoot, Value: ValueNumber, Composed: ValueNumber},
+
+		// Game info properties
+		{Ident: "AN", Name: "Annotation", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "BR", Name: "Black rank", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "BT", Name: "Black team", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "CP", Name: "Copyright", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "DT", Name: "Date", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "EV", Name: "Event", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "GC", Name: "Game comment", Type: PropertyTypeGameInfo, Value: ValueText},
+		{Ident: "GN", Name: "Game name", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "ON", Name: "Opening", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "OT", Name: "Overtime", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "PB", Name: "Player Black", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "PC", Name: "Place", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "PW", Name: "Player White", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "RE", Name: "Result", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "RO", Name: "Round", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "RU", Name: "Rules", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "SO", Name: "Source", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "TM", Name: "Timelimit", Type: PropertyTypeGameInfo, Value: ValueReal},
+		{Ident: "US", Name: "User", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "WR", Name: "White rank", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+		{Ident: "WT", Name: "White team", Type: PropertyTypeGameInfo, Value: ValueSimpleText},
+
+		// Timing properties
+		{Ident: "BL", Name: "Black time left", Type: PropertyTypeMove, Value: ValueReal},
+		{Ident: "OB", Name: "OtStones Black", Type: PropertyTypeMove, Value: ValueNumber},
+		{Ident: "OW", Name: "OtStones White", Type: PropertyTypeMove, Value: ValueNumber},
+		{Ident: "WL", Name: "White time left", Type: PropertyTypeMove, Value: ValueReal},
+
+		// Miscellaneous properties
+		{Ident: "FG", Name: "Figure", Value: ValueNumber, Composed: ValueSimpleText, EList: true},
+		{Ident: "PM", Name: "Print move mode", Value: ValueNumber, Inherit: true},
+		{Ident: "VW", Name: "View", Value: ValuePoint, List: true, EList: true, Inherit: true},
+
+		// Go specific properties
+		{Ident: "HA", Name: "Handicap", Type: PropertyTypeGameInfo, Value: ValueNumber},
+		{Ident: "KM", Name: "Komi", Type: PropertyTypeGameInfo, Value: ValueReal},
+		{Ident: "TB", Name: "Territory Black", Value: ValuePoint, List: true, EList: true},
+		{Ident: "TW", Name: "Territory White", Value: ValuePoint, List: true, EList: true},
+	} {
+		RegisterProperty(info)
+	}
+}
+
+// Returns the registered information of the property with the given ident. The second return value is false if
+// the property is not registered.
+func LookupProperty(ident string) (PropertyInfo, bool) {
+	info, ok := properties[ident]
+	return info, ok
+}
+
+// Registers a property, e.g. an application specific private property. Registering an already registered ident
+// replaces the old information.
+func RegisterProperty(info PropertyInfo) {
+	properties[info.Ident] = info
+}
+
+// Returns the registered information of this Property. Unknown properties get ValueUnknown value type.
+func (property *Property) Info() PropertyInfo {
+	if info, ok := LookupProperty(property.Ident); ok {
+		return info
+	}
+
+	return PropertyInfo{Ident: property.Ident, Name: property.Ident}
+}
+
+// Returns the values of this Property decoded according to the value type in the property registry. Text values are
+// decoded as described by DecodeText and SimpleText values as described by DecodeSimpleText. Parts of composed values
+// are decoded separately and the result is in Compose form. Other values are returned as is. The Values field keeps
+// the values as parsed: escaping is removed (see Property for composed values) but the text is not decoded.
+func (property *Property) DecodedValues() []string {
+	info := property.Info()
+	values := make([]string, len(property.Values))
+
+	for i, value := range property.Values {
+		if info.Composed == ValueUnknown {
+			values[i] = decodeValue(info.Value, value)
+			continue
+		}
+
+		// Composed values are decoded part by part
+		first, second, ok := Compose(value).Split()
+		if ok {
+			values[i] = string(NewCompose(decodeValue(info.Value, first), decodeValue(info.Composed, second)))
+		} else {
+			values[i] = decodeValue(info.Value, first)
+		}
+	}
+
+	return values
+}
+
+func decodeValue(valueType ValueType, value string) string {
+	switch valueType {
+	case ValueText:
+		return normalizeText(value)
+	case ValueSimpleText:
+		return normalizeSimpleText(value)
+	}
+
+	return value
+}
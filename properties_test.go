@@ -0,0 +1,66 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestLookupProperty(t *testing.T) {
+	info, ok := LookupProperty("C")
+	if !ok || info.Value != ValueText {
+		t.Errorf("LookupProperty(C) mismatch. Got: %v.", info)
+	}
+
+	info, ok = LookupProperty("LB")
+	if !ok || info.Value != ValuePoint || info.Composed != ValueSimpleText || !info.List {
+		t.Errorf("LookupProperty(LB) mismatch. Got: %v.", info)
+	}
+
+	if _, ok := LookupProperty("XX"); ok {
+		t.Errorf("LookupProperty(XX) found unknown property.")
+	}
+
+	if info := (&Property{"XX", []string{"1"}}).Info(); info.Ident != "XX" || info.Value != ValueUnknown {
+		t.Errorf("Property.Info() mismatch for unknown property. Got: %v.", info)
+	}
+}
+
+func TestRegisterProperty(t *testing.T) {
+	defer delete(properties, "XY")
+
+	RegisterProperty(PropertyInfo{Ident: "XY", Name: "Private", Value: ValueSimpleText})
+
+	if info, ok := LookupProperty("XY"); !ok || info.Name != "Private" {
+		t.Errorf("RegisterProperty() did not register the property.")
+	}
+}
+
+func TestDecodedValues(t *testing.T) {
+	collection, err := ParseSgf("(;C[first\\\r\nline\r\n\tsecond]PB[Lee\r\nSedol]LB[aa:a\tb]SZ[19])")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error.")
+	}
+
+	var tests = []struct {
+		ident  string
+		raw    string
+		wanted string
+	}{
+		{"C", "firstline\r\n\tsecond", "firstline\n second"},
+		{"PB", "Lee\r\nSedol", "Lee Sedol"},
+		{"LB", "aa:a\tb", "aa:a b"},
+		{"SZ", "19", "19"},
+	}
+
+	node := collection.GameTrees[0].Nodes[0]
+	for _, test := range tests {
+		property := node.Property(test.ident)
+
+		if property.Values[0] != test.raw {
+			t.Errorf("Property %s raw value mismatch. wanted: %q, got: %q.", test.ident, test.raw, property.Values[0])
+		}
+
+		if value := property.DecodedValues()[0]; value != test.wanted {
+			t.Errorf("Property %s decoded value mismatch. wanted: %q, got: %q.", test.ident, test.wanted, value)
+		}
+	}
+}
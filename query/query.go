@@ -0,0 +1,591 @@
+/*
+Package query finds Nodes of sgf game records with a small query language.
+
+A query is a boolean expression evaluated for every Node of a Collection:
+
+	root.PB ~ "Lee Sedol" and RE startswith "W+"
+	node has C and depth < 50
+	not (has B or has W) and (has AB or has AW)
+	KM >= 6.5 and root.DT >= "2016"
+
+References:
+
+	XX          values of property XX of the Node, same as node.XX
+	root.XX     values of property XX of the root Node of the GameTree
+	depth       number of Nodes above the Node, 0 for the root
+	move        number of moves (B or W) from the root to the Node
+	game        number of the GameTree in the Collection, counted from 1
+	children    number of Nodes following the Node, more than 1 at a branch
+
+Operators:
+
+	=, !=                equal values, numbers are compared as numbers
+	<, <=, >, >=         numeric order if both sides are numbers, string order otherwise
+	~, !~                value matches the regular expression (Go syntax)
+	contains, startswith, endswith
+	has XX, node has XX, root has XX
+	and, or, not, ( )
+
+A comparison is true if any value of the property satisfies it and false if the property is missing. Properties with
+Number, Real or Double values (see sgf.LookupProperty) are compared as numbers and Text and SimpleText values as
+decoded by sgf.Property.DecodedValues. Strings are written in double quotes with Go escapes.
+*/
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+// Query is a compiled query.
+type Query struct {
+	source string
+	expr   expr
+}
+
+// Node matching a query.
+type Match struct {
+	Game       int         // index of the GameTree in the Collection
+	Path       []*sgf.Node // Nodes from the root to the matching Node
+	Variations []int       // index of the GameTree chosen at each branch of the path, all 0 on the main line
+}
+
+// Returns the matching Node.
+func (match Match) Node() *sgf.Node {
+	return match.Path[len(match.Path)-1]
+}
+
+// Returns the variations of the match as text, e.g. "1.0.2", or an empty string if the Node is on the main line.
+func (match Match) VariationString() string {
+	main := true
+	parts := make([]string, len(match.Variations))
+
+	for i, variation := range match.Variations {
+		parts[i] = strconv.Itoa(variation)
+		main = main && variation == 0
+	}
+
+	if main {
+		return ""
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// Compiles the query.
+func Compile(source string) (*Query, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, createError(fmt.Sprintf("Unexpected %q", t.text), t.position)
+	}
+
+	return &Query{source, e}, nil
+}
+
+// Compiles the query and panics if it's not valid.
+func MustCompile(source string) *Query {
+	query, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+
+	return query
+}
+
+// Returns the source of the query.
+func (query *Query) String() string {
+	return query.source
+}
+
+// Returns the Nodes of the collection matching the query in the order of the GameTrees, main line before the
+// variations.
+func (query *Query) Match(collection *sgf.Collection) []Match {
+	matches := []Match{}
+
+	for i, gameTree := range collection.GameTrees {
+		c := &context{game: i}
+		matches = query.matchGameTree(matches, gameTree, c)
+	}
+
+	return matches
+}
+
+func (query *Query) matchGameTree(matches []Match, gameTree *sgf.GameTree, c *context) []Match {
+	pathLength, variationsLength, moves := len(c.path), len(c.variations), c.moves
+
+	for i, node := range gameTree.Nodes {
+		c.path = append(c.path, node)
+		if node.Property("B") != nil || node.Property("W") != nil {
+			c.moves++
+		}
+
+		c.children = len(gameTree.GameTrees)
+		if i < len(gameTree.Nodes)-1 {
+			c.children = 1
+		}
+
+		if query.expr.eval(c) {
+			matches = append(matches, Match{
+				Game:       c.game,
+				Path:       append([]*sgf.Node{}, c.path...),
+				Variations: append([]int{}, c.variations...),
+			})
+		}
+	}
+
+	for i, child := range gameTree.GameTrees {
+		if len(gameTree.GameTrees) > 1 {
+			c.variations = append(c.variations, i)
+		}
+
+		matches = query.matchGameTree(matches, child, c)
+		c.variations = c.variations[:variationsLength]
+	}
+
+	c.path, c.variations, c.moves = c.path[:pathLength], c.variations[:variationsLength], moves
+
+	return matches
+}
+
+// Evaluation context: the Node being matched and its position.
+type context struct {
+	game       int
+	path       []*sgf.Node
+	variations []int
+	moves      int
+	children   int
+}
+
+func (c *context) node() *sgf.Node {
+	return c.path[len(c.path)-1]
+}
+
+//
+// Expressions
+//
+
+type expr interface {
+	eval(c *context) bool
+}
+
+type orExpr struct{ left, right expr }
+
+func (e orExpr) eval(c *context) bool { return e.left.eval(c) || e.right.eval(c) }
+
+type andExpr struct{ left, right expr }
+
+func (e andExpr) eval(c *context) bool { return e.left.eval(c) && e.right.eval(c) }
+
+type notExpr struct{ e expr }
+
+func (e notExpr) eval(c *context) bool { return !e.e.eval(c) }
+
+// Property or field reference.
+type ref struct {
+	root  bool   // property of the root Node
+	ident string // property ident, empty for fields
+	field string
+}
+
+// Returns the values of the reference and whether they are numbers.
+func (r ref) values(c *context) ([]string, bool) {
+	switch r.field {
+	case "depth":
+		return []string{strconv.Itoa(len(c.path) - 1)}, true
+	case "move":
+		return []string{strconv.Itoa(c.moves)}, true
+	case "game":
+		return []string{strconv.Itoa(c.game + 1)}, true
+	case "children":
+		return []string{strconv.Itoa(c.children)}, true
+	}
+
+	node := c.node()
+	if r.root {
+		node = c.path[0]
+	}
+
+	property := node.Property(r.ident)
+	if property == nil {
+		return nil, false
+	}
+
+	switch property.Info().Value {
+	case sgf.ValueNumber, sgf.ValueReal, sgf.ValueDouble:
+		return property.Values, true
+	}
+
+	return property.DecodedValues(), false
+}
+
+type hasExpr struct{ ref ref }
+
+func (e hasExpr) eval(c *context) bool {
+	values, _ := e.ref.values(c)
+	return values != nil
+}
+
+type compareExpr struct {
+	ref     ref
+	op      string
+	literal string
+	pattern *regexp.Regexp // compiled literal of ~ and !~
+}
+
+func (e compareExpr) eval(c *context) bool {
+	values, numeric := e.ref.values(c)
+
+	for _, value := range values {
+		if e.compare(value, numeric) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e compareExpr) compare(value string, numeric bool) bool {
+	switch e.op {
+	case "~":
+		return e.pattern.MatchString(value)
+	case "!~":
+		return !e.pattern.MatchString(value)
+	case "contains":
+		return strings.Contains(value, e.literal)
+	case "startswith":
+		return strings.HasPrefix(value, e.literal)
+	case "endswith":
+		return strings.HasSuffix(value, e.literal)
+	}
+
+	// Numbers are compared as numbers when both sides are numbers, typed properties always
+	order := strings.Compare(value, e.literal)
+
+	a, errA := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	b, errB := strconv.ParseFloat(e.literal, 64)
+
+	switch {
+	case errA == nil && errB == nil:
+		order = compareNumbers(a, b)
+	case numeric:
+		return false
+	}
+
+	switch e.op {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+
+	return false
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+//
+// Tokenizer
+//
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenWord
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenDot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind     tokenKind
+	text     string // word, operator or the value of a string
+	position int    // byte offset in the query
+}
+
+var (
+	wordPattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+	numberPattern   = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?`)
+	stringPattern   = regexp.MustCompile(`^"(\\.|[^"\\])*"`)
+	operatorPattern = regexp.MustCompile(`^(==|!=|<=|>=|!~|=|<|>|~)`)
+)
+
+func tokenize(source string) ([]token, error) {
+	tokens := []token{}
+
+	for i := 0; i < len(source); {
+		rest := source[i:]
+
+		switch {
+		case strings.TrimLeft(rest[:1], " \t\r\n") == "":
+			i++
+			continue
+		case rest[0] == '(':
+			tokens = append(tokens, token{tokenOpen, "(", i})
+			i++
+		case rest[0] == ')':
+			tokens = append(tokens, token{tokenClose, ")", i})
+			i++
+		case rest[0] == '.':
+			tokens = append(tokens, token{tokenDot, ".", i})
+			i++
+		case rest[0] == '"':
+			match := stringPattern.FindString(rest)
+			if match == "" {
+				return nil, createError("String is not closed", i)
+			}
+
+			value, err := strconv.Unquote(match)
+			if err != nil {
+				return nil, createError(fmt.Sprintf("Invalid string %s", match), i)
+			}
+
+			tokens = append(tokens, token{tokenString, value, i})
+			i += len(match)
+		case numberPattern.MatchString(rest):
+			match := numberPattern.FindString(rest)
+			tokens = append(tokens, token{tokenNumber, match, i})
+			i += len(match)
+		case wordPattern.MatchString(rest):
+			match := wordPattern.FindString(rest)
+			tokens = append(tokens, token{tokenWord, match, i})
+			i += len(match)
+		case operatorPattern.MatchString(rest):
+			match := operatorPattern.FindString(rest)
+			operator := match
+			if operator == "==" {
+				operator = "="
+			}
+
+			tokens = append(tokens, token{tokenOperator, operator, i})
+			i += len(match)
+		default:
+			return nil, createError(fmt.Sprintf("Invalid character %q", rest[0]), i)
+		}
+	}
+
+	return append(tokens, token{tokenEnd, "end of query", len(source)}), nil
+}
+
+//
+// Parser
+//
+
+// Word operators of the comparisons.
+var wordOperators = map[string]bool{"contains": true, "startswith": true, "endswith": true}
+
+var fields = map[string]bool{"depth": true, "move": true, "game": true, "children": true}
+
+var identPattern = regexp.MustCompile(`^[A-Z]+$`)
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEnd {
+		p.i++
+	}
+
+	return t
+}
+
+// Consumes the next token if it's the given word.
+func (p *parser) accept(word string) bool {
+	if t := p.peek(); t.kind == tokenWord && t.text == word {
+		p.i++
+		return true
+	}
+
+	return false
+}
+
+// or = and { "or" and }
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orExpr{left, right}
+	}
+
+	return left, nil
+}
+
+// and = unary { "and" unary }
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andExpr{left, right}
+	}
+
+	return left, nil
+}
+
+// unary = "not" unary | "(" or ")" | "has" ref | scope "has" ident | ref operator literal
+func (p *parser) parseUnary() (expr, error) {
+	if p.accept("not") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{e}, nil
+	}
+
+	if p.peek().kind == tokenOpen {
+		p.next()
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if t := p.next(); t.kind != tokenClose {
+			return nil, createError(fmt.Sprintf("Expected ) but got %q", t.text), t.position)
+		}
+
+		return e, nil
+	}
+
+	if p.accept("has") {
+		r, err := p.parseRef()
+		if err != nil {
+			return nil, err
+		}
+
+		return hasExpr{r}, nil
+	}
+
+	// "root has XX" and "node has XX"
+	if t := p.peek(); t.kind == tokenWord && (t.text == "root" || t.text == "node") {
+		if next := p.tokens[p.i+1]; next.kind == tokenWord && next.text == "has" {
+			p.i += 2
+
+			ident := p.next()
+			if ident.kind != tokenWord || !identPattern.MatchString(ident.text) {
+				return nil, createError(fmt.Sprintf("Expected property ident but got %q", ident.text), ident.position)
+			}
+
+			return hasExpr{ref{root: t.text == "root", ident: ident.text}}, nil
+		}
+	}
+
+	r, err := p.parseRef()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	if op.kind != tokenOperator && !(op.kind == tokenWord && wordOperators[op.text]) {
+		return nil, createError(fmt.Sprintf("Expected operator but got %q", op.text), op.position)
+	}
+
+	literal := p.next()
+	if literal.kind != tokenString && literal.kind != tokenNumber {
+		return nil, createError(fmt.Sprintf("Expected string or number but got %q", literal.text), literal.position)
+	}
+
+	e := compareExpr{ref: r, op: op.text, literal: literal.text}
+
+	if op.text == "~" || op.text == "!~" {
+		if e.pattern, err = regexp.Compile(literal.text); err != nil {
+			return nil, createError(fmt.Sprintf("Invalid regular expression: %s", err), literal.position)
+		}
+	}
+
+	return e, nil
+}
+
+// ref = [ ("root" | "node") "." ] ident | field
+func (p *parser) parseRef() (ref, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return ref{}, createError(fmt.Sprintf("Expected property or field but got %q", t.text), t.position)
+	}
+
+	if fields[t.text] {
+		return ref{field: t.text}, nil
+	}
+
+	r := ref{}
+
+	if t.text == "root" || t.text == "node" {
+		if dot := p.next(); dot.kind != tokenDot {
+			return ref{}, createError(fmt.Sprintf("Expected . but got %q", dot.text), dot.position)
+		}
+
+		r.root = t.text == "root"
+		t = p.next()
+	}
+
+	if t.kind != tokenWord || !identPattern.MatchString(t.text) {
+		return ref{}, createError(fmt.Sprintf("Expected property ident but got %q", t.text), t.position)
+	}
+
+	r.ident = t.text
+
+	return r, nil
+}
+
+func createError(msg string, position int) error {
+	return errors.New(fmt.Sprintf("%s [position %d]", msg, position+1))
+}
@@ -0,0 +1,129 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+const games = "(;PB[Lee Sedol]PW[AlphaGo]RE[W+Resign]KM[7.5]DT[2016-03-09];B[pd]C[first];W[dd]" +
+	"(;B[pq];W[dp]C[main])" +
+	"(;B[qp]N[x];W[oc]C[variation\\\nline]))" +
+	"(;PB[Ke Jie]PW[AlphaGo]RE[W+0.5]KM[7.50]DT[2017-05-23];AB[aa][bb];B[cc])"
+
+func TestMatch(t *testing.T) {
+	collection, err := sgf.ParseSgf(games)
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	var tests = []struct {
+		query   string
+		matches []string // game, depth and variations of the matches
+	}{
+		{`root.PB ~ "Lee Sedol" and RE startswith "W+"`, []string{"0/0/"}},
+		{`node has C and depth < 5`, []string{"0/1/", "0/4/0", "0/4/1"}},
+		{`has C and move >= 4`, []string{"0/4/0", "0/4/1"}},
+		{`C = "variationline"`, []string{"0/4/1"}},
+		{`C contains "line" or N = "x"`, []string{"0/3/1", "0/4/1"}},
+		{`KM = 7.5`, []string{"0/0/", "1/0/"}},
+		{`KM == "7.50"`, []string{"0/0/", "1/0/"}},
+		{`KM < 7`, []string{}},
+		{`root.DT >= "2017" and has B`, []string{"1/2/"}},
+		{`not (has B or has W) and (has AB or has AW)`, []string{"1/1/"}},
+		{`children > 1`, []string{"0/2/"}},
+		{`game = 2 and depth = 0`, []string{"1/0/"}},
+		{`RE !~ "^W\\+[0-9]" and root has RE`, []string{"0/0/"}},
+		{`B endswith "q" or W = "oc"`, []string{"0/3/0", "0/4/1"}},
+		{`XX = "1" or not has XX and depth = 6`, []string{}},
+	}
+
+	for _, test := range tests {
+		query, err := Compile(test.query)
+		if err != nil {
+			t.Errorf("Compile(%s) returned error: %s", test.query, err)
+			continue
+		}
+
+		matches := []string{}
+		for _, match := range query.Match(collection) {
+			matches = append(matches, matchString(match))
+		}
+
+		if !reflect.DeepEqual(matches, test.matches) {
+			t.Errorf("Match(%s) mismatch. Wanted: %v, got: %v", test.query, test.matches, matches)
+		}
+	}
+}
+
+func matchString(match Match) string {
+	s := ""
+	for _, variation := range match.Variations {
+		s += string(rune('0' + variation))
+	}
+
+	return string(rune('0'+match.Game)) + "/" + string(rune('0'+len(match.Path)-1)) + "/" + s
+}
+
+func TestMatchPath(t *testing.T) {
+	collection, _ := sgf.ParseSgf(games)
+
+	matches := MustCompile(`W = "oc"`).Match(collection)
+	if len(matches) != 1 {
+		t.Fatalf("Match() returned %d matches", len(matches))
+	}
+
+	match := matches[0]
+	gameTree := collection.GameTrees[0]
+
+	if !reflect.DeepEqual(match.Path, gameTree.PathTo(match.Node())) {
+		t.Errorf("Match() path mismatch.")
+	}
+
+	if match.VariationString() != "1" {
+		t.Errorf("VariationString() mismatch. Got: %s", match.VariationString())
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	var tests = []string{
+		``,
+		`has`,
+		`PB`,
+		`PB = `,
+		`PB = Lee`,
+		`PB "x"`,
+		`pb = "x"`,
+		`(has B`,
+		`has B)`,
+		`has B and`,
+		`root.`,
+		`root.pb = "x"`,
+		`node has 1`,
+		`C ~ "("`,
+		`C = "open`,
+		`C = 'x'`,
+	}
+
+	for _, test := range tests {
+		if _, err := Compile(test); err == nil {
+			t.Errorf("Compile(%s) did not return error.", test)
+		}
+	}
+
+	if !fnPanics(func() { MustCompile("has") }) {
+		t.Errorf("MustCompile() did not panic.")
+	}
+}
+
+func fnPanics(fn func()) (b bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			b = true
+		}
+	}()
+
+	fn()
+	return b
+}
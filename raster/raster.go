@@ -0,0 +1,496 @@
+/*
+Package raster draws board positions of sgf game records as PNG images and game lines as animated GIF images.
+
+Only the standard image packages are used. The images show the grid, hoshi points, stones, the last move and the
+shapes (CR, SQ, TR, MA, SL), arrows (AR) and lines (LN) of the Node. Points dimmed by DD are faded and the visible area
+(VW) can be used to crop the image. Labels (LB) and move numbers are not drawn because the standard library does not
+provide fonts.
+*/
+package raster
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/toikarin/sgf"
+)
+
+// Options for drawing the images.
+type Options struct {
+	PointSize   int     // distance of the grid lines in pixels
+	Hoshi       bool    // draw hoshi points
+	LastMove    bool    // mark the last move with a circle
+	Markup      bool    // draw the shapes, arrows and lines of the Node
+	Crop        bool    // draw only the visible area (VW) in effect
+	DimOpacity  float64 // opacity of the board color drawn over dimmed points
+	BoardColor  color.RGBA
+	LineColor   color.RGBA
+	BlackColor  color.RGBA
+	WhiteColor  color.RGBA
+	MarkupColor color.RGBA // color of markup on empty points and white stones
+}
+
+var (
+	// Default options.
+	DefaultOptions = Options{
+		PointSize:   24,
+		Hoshi:       true,
+		LastMove:    true,
+		Markup:      true,
+		Crop:        true,
+		DimOpacity:  0.6,
+		BoardColor:  color.RGBA{0xdc, 0xb3, 0x5c, 0xff},
+		LineColor:   color.RGBA{0x00, 0x00, 0x00, 0xff},
+		BlackColor:  color.RGBA{0x00, 0x00, 0x00, 0xff},
+		WhiteColor:  color.RGBA{0xff, 0xff, 0xff, 0xff},
+		MarkupColor: color.RGBA{0x00, 0x00, 0x00, 0xff},
+	}
+)
+
+// Options for animated GIF images.
+type GIFOptions struct {
+	Delay      int // delay after each move in 100ths of a second
+	FinalDelay int // delay after the last move in 100ths of a second
+	LoopCount  int // 0 = loop forever, -1 = show once
+}
+
+// Default GIF options.
+var DefaultGIFOptions = GIFOptions{Delay: 100, FinalDelay: 500}
+
+// Sub-pixels per pixel in each direction used for anti-aliasing.
+const samples = 4
+
+// Drawing state of a single image.
+type drawer struct {
+	image   *image.RGBA
+	options Options
+	board   *sgf.Board
+	x1, y1  int     // upper left corner of the drawn area
+	x2, y2  int     // lower right corner of the drawn area
+	margin  float64 // space around the grid
+	width   float64 // width of the grid lines
+}
+
+// Draws the position after the last Node of the path (see sgf.GameTree.PathTo).
+func Image(path []*sgf.Node, options Options) (*image.RGBA, error) {
+	if options.PointSize < 4 {
+		return nil, errors.New("Point size must be at least 4 pixels")
+	}
+
+	board, err := sgf.Position(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &drawer{options: options, board: board, x2: board.Width - 1, y2: board.Height - 1}
+	d.margin = float64(options.PointSize)
+	d.width = math.Max(1, math.Round(float64(options.PointSize)/24))
+
+	if options.Crop {
+		topLeft, bottomRight, err := sgf.ViewRectangle(path, board.Width, board.Height)
+		if err != nil {
+			return nil, err
+		}
+
+		d.x1, d.y1, d.x2, d.y2 = topLeft.X, topLeft.Y, bottomRight.X, bottomRight.Y
+	}
+
+	width := int(2*d.margin+d.width) + (d.x2-d.x1)*options.PointSize
+	height := int(2*d.margin+d.width) + (d.y2-d.y1)*options.PointSize
+
+	d.image = image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(d.image, d.image.Bounds(), image.NewUniform(options.BoardColor), image.Point{}, draw.Src)
+
+	d.drawGrid()
+	d.drawStones()
+
+	node := path[len(path)-1]
+
+	if options.LastMove {
+		color, point, pass, err := node.Move(board.Width, board.Height)
+		if err != nil {
+			return nil, err
+		}
+
+		if color != sgf.Empty && !pass {
+			d.drawShape(point, sgf.ShapeCircle)
+		}
+	}
+
+	if options.Markup {
+		if err := d.drawMarkup(node); err != nil {
+			return nil, err
+		}
+	}
+
+	dimmed, err := sgf.InheritedDimmed(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := float64(options.PointSize)
+	dim := options.BoardColor
+	dim.A = uint8(math.Round(options.DimOpacity * 0xff))
+	for _, point := range dimmed {
+		if d.visible(point) {
+			x, y := d.center(point)
+			d.fill(x-s/2, y-s/2, x+s/2, y+s/2, dim, func(px, py float64) bool { return true })
+		}
+	}
+
+	return d.image, nil
+}
+
+// Writes the position after the last Node of the path as PNG.
+func PNG(w io.Writer, path []*sgf.Node, options Options) error {
+	img, err := Image(path, options)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// Writes an animated GIF of the line. Each Node of the line is a frame showing the position after the Node. The
+// size of the frames is the size of the first frame, so the view (VW) of the first Node decides the cropping.
+func GIF(w io.Writer, line []*sgf.Node, options Options, gifOptions GIFOptions) error {
+	if len(line) == 0 {
+		return errors.New("Empty line")
+	}
+
+	palette := gifPalette(options)
+	animation := &gif.GIF{LoopCount: gifOptions.LoopCount}
+
+	var bounds image.Rectangle
+	for i := range line {
+		img, err := Image(line[:i+1], options)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			bounds = img.Bounds()
+		}
+
+		frame := image.NewPaletted(bounds, palette)
+		draw.Draw(frame, bounds, img, image.Point{}, draw.Src)
+
+		delay := gifOptions.Delay
+		if i == len(line)-1 {
+			delay = gifOptions.FinalDelay
+		}
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, delay)
+	}
+
+	return gif.EncodeAll(w, animation)
+}
+
+// Returns the palette of the GIF frames: the colors of the options and blends between them for the anti-aliased
+// edges.
+func gifPalette(options Options) color.Palette {
+	palette := color.Palette{}
+
+	pairs := [][2]color.RGBA{
+		{options.BoardColor, options.LineColor},
+		{options.BoardColor, options.BlackColor},
+		{options.BoardColor, options.WhiteColor},
+		{options.BoardColor, options.MarkupColor},
+		{options.BlackColor, options.WhiteColor},
+		{options.WhiteColor, options.MarkupColor},
+		{options.WhiteColor, options.LineColor},
+	}
+
+	seen := map[color.RGBA]bool{}
+	for _, pair := range pairs {
+		for i := 0; i <= 16; i++ {
+			c := blend(pair[0], pair[1], float64(i)/16)
+			if !seen[c] {
+				seen[c] = true
+				palette = append(palette, c)
+			}
+		}
+	}
+
+	if len(palette) > 256 {
+		palette = palette[:256]
+	}
+
+	return palette
+}
+
+// Returns the x coordinate of the left edge of the grid line of the column.
+func (d *drawer) cx(x int) float64 {
+	return d.margin + float64((x-d.x1)*d.options.PointSize)
+}
+
+// Returns the y coordinate of the top edge of the grid line of the row.
+func (d *drawer) cy(y int) float64 {
+	return d.margin + float64((y-d.y1)*d.options.PointSize)
+}
+
+// Returns the center of the point.
+func (d *drawer) center(point sgf.Point) (float64, float64) {
+	return d.cx(point.X) + d.width/2, d.cy(point.Y) + d.width/2
+}
+
+func (d *drawer) visible(point sgf.Point) bool {
+	return point.X >= d.x1 && point.X <= d.x2 && point.Y >= d.y1 && point.Y <= d.y2
+}
+
+func (d *drawer) drawGrid() {
+	s := float64(d.options.PointSize)
+	w := d.width
+
+	// Lines continue half a point over the edges of the cropped area
+	extend := func(c, edge int) float64 {
+		if c == edge {
+			return 0
+		}
+
+		return s / 2
+	}
+
+	left, right := d.cx(d.x1)-extend(d.x1, 0), d.cx(d.x2)+extend(d.x2, d.board.Width-1)
+	top, bottom := d.cy(d.y1)-extend(d.y1, 0), d.cy(d.y2)+extend(d.y2, d.board.Height-1)
+
+	all := func(px, py float64) bool { return true }
+
+	for y := d.y1; y <= d.y2; y++ {
+		d.fill(left, d.cy(y), right+w, d.cy(y)+w, d.options.LineColor, all)
+	}
+
+	for x := d.x1; x <= d.x2; x++ {
+		d.fill(d.cx(x), top, d.cx(x)+w, bottom+w, d.options.LineColor, all)
+	}
+
+	if d.options.Hoshi {
+		for _, point := range sgf.StarPoints(d.board.Width, d.board.Height) {
+			if d.visible(point) {
+				x, y := d.center(point)
+				d.circle(x, y, s*0.1, d.options.LineColor)
+			}
+		}
+	}
+}
+
+func (d *drawer) drawStones() {
+	r := float64(d.options.PointSize) * 0.48
+
+	for y := d.y1; y <= d.y2; y++ {
+		for x := d.x1; x <= d.x2; x++ {
+			cx, cy := d.center(sgf.Point{X: x, Y: y})
+
+			switch d.board.At(sgf.Point{X: x, Y: y}) {
+			case sgf.Black:
+				d.circle(cx, cy, r, d.options.BlackColor)
+			case sgf.White:
+				d.circle(cx, cy, r, d.options.LineColor)
+				d.circle(cx, cy, r-d.width, d.options.WhiteColor)
+			}
+		}
+	}
+}
+
+func (d *drawer) drawMarkup(node *sgf.Node) error {
+	shapes, err := node.Shapes()
+	if err != nil {
+		return err
+	}
+
+	for y := d.y1; y <= d.y2; y++ {
+		for x := d.x1; x <= d.x2; x++ {
+			if shape := shapes[sgf.Point{X: x, Y: y}]; shape != sgf.ShapeNone {
+				d.drawShape(sgf.Point{X: x, Y: y}, shape)
+			}
+		}
+	}
+
+	lines, err := node.Lines()
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		d.drawLine(line, false)
+	}
+
+	arrows, err := node.Arrows()
+	if err != nil {
+		return err
+	}
+
+	for _, arrow := range arrows {
+		d.drawLine(arrow, true)
+	}
+
+	return nil
+}
+
+// Returns the color of the markup drawn on the point.
+func (d *drawer) markupColor(point sgf.Point) color.RGBA {
+	if d.board.At(point) == sgf.Black {
+		return d.options.WhiteColor
+	}
+
+	return d.options.MarkupColor
+}
+
+func (d *drawer) drawShape(point sgf.Point, shape sgf.Shape) {
+	if !d.visible(point) {
+		return
+	}
+
+	x, y := d.center(point)
+	s := float64(d.options.PointSize)
+	w := math.Max(1, s*0.08)
+	c := d.markupColor(point)
+
+	switch shape {
+	case sgf.ShapeCircle:
+		r := s * 0.25
+		d.fill(x-r-w, y-r-w, x+r+w, y+r+w, c, func(px, py float64) bool {
+			dist := math.Hypot(px-x, py-y)
+			return dist <= r+w/2 && dist >= r-w/2
+		})
+	case sgf.ShapeSquare:
+		a := s * 0.22
+		d.fill(x-a-w, y-a-w, x+a+w, y+a+w, c, func(px, py float64) bool {
+			dx, dy := math.Abs(px-x), math.Abs(py-y)
+			return dx <= a+w/2 && dy <= a+w/2 && (dx >= a-w/2 || dy >= a-w/2)
+		})
+	case sgf.ShapeTriangle:
+		r := s * 0.3
+		outer := triangle(x, y, r+w)
+		inner := triangle(x, y, r-w)
+		d.fill(x-r-2*w, y-r-2*w, x+r+2*w, y+r+2*w, c, func(px, py float64) bool {
+			return inTriangle(px, py, outer) && !inTriangle(px, py, inner)
+		})
+	case sgf.ShapeCross:
+		r := s * 0.2
+		d.fill(x-r-w, y-r-w, x+r+w, y+r+w, c, func(px, py float64) bool {
+			return segmentDistance(px, py, x-r, y-r, x+r, y+r) <= w/2 || segmentDistance(px, py, x-r, y+r, x+r, y-r) <= w/2
+		})
+	case sgf.ShapeSelected:
+		c.A = 0x66
+		d.fill(x-s/2, y-s/2, x+s/2, y+s/2, c, func(px, py float64) bool { return true })
+	}
+}
+
+func (d *drawer) drawLine(line sgf.Line, arrow bool) {
+	x1, y1 := d.center(line.From)
+	x2, y2 := d.center(line.To)
+	s := float64(d.options.PointSize)
+	w := math.Max(1, s*0.08)
+
+	d.fill(math.Min(x1, x2)-w, math.Min(y1, y2)-w, math.Max(x1, x2)+w, math.Max(y1, y2)+w, d.options.MarkupColor,
+		func(px, py float64) bool {
+			return segmentDistance(px, py, x1, y1, x2, y2) <= w/2
+		})
+
+	if !arrow || (x1 == x2 && y1 == y2) {
+		return
+	}
+
+	// Arrow head at the end point
+	angle := math.Atan2(y2-y1, x2-x1)
+	size := s * 0.35
+	head := [3][2]float64{
+		{x2, y2},
+		{x2 - size*math.Cos(angle-math.Pi/7), y2 - size*math.Sin(angle-math.Pi/7)},
+		{x2 - size*math.Cos(angle+math.Pi/7), y2 - size*math.Sin(angle+math.Pi/7)},
+	}
+
+	d.fill(x2-size, y2-size, x2+size, y2+size, d.options.MarkupColor, func(px, py float64) bool {
+		return inTriangle(px, py, head)
+	})
+}
+
+func (d *drawer) circle(x, y, r float64, c color.RGBA) {
+	d.fill(x-r, y-r, x+r, y+r, c, func(px, py float64) bool {
+		return math.Hypot(px-x, py-y) <= r
+	})
+}
+
+// Fills the area inside the rectangle where the inside function returns true. Edges are anti-aliased by sampling
+// each pixel several times. Alpha of the color is used as the opacity of the fill.
+func (d *drawer) fill(left, top, right, bottom float64, c color.RGBA, inside func(x, y float64) bool) {
+	bounds := d.image.Bounds()
+	opacity := float64(c.A) / 0xff
+	c.A = 0xff
+
+	for py := int(math.Floor(top)); py < int(math.Ceil(bottom)); py++ {
+		for px := int(math.Floor(left)); px < int(math.Ceil(right)); px++ {
+			if !(image.Point{px, py}).In(bounds) {
+				continue
+			}
+
+			count := 0
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					x := float64(px) + (float64(sx)+0.5)/samples
+					y := float64(py) + (float64(sy)+0.5)/samples
+
+					if x >= left && x <= right && y >= top && y <= bottom && inside(x, y) {
+						count++
+					}
+				}
+			}
+
+			if count > 0 {
+				coverage := float64(count) / (samples * samples) * opacity
+				d.image.SetRGBA(px, py, blend(d.image.RGBAAt(px, py), c, coverage))
+			}
+		}
+	}
+}
+
+// Returns the color between the colors, amount 0 is the first color and 1 the second one.
+func blend(c1, c2 color.RGBA, amount float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a)*(1-amount) + float64(b)*amount))
+	}
+
+	return color.RGBA{mix(c1.R, c2.R), mix(c1.G, c2.G), mix(c1.B, c2.B), mix(c1.A, c2.A)}
+}
+
+// Returns the corners of an upwards pointing equilateral triangle with the given circumradius.
+func triangle(x, y, r float64) [3][2]float64 {
+	return [3][2]float64{
+		{x, y - r},
+		{x - r*math.Sqrt(3)/2, y + r/2},
+		{x + r*math.Sqrt(3)/2, y + r/2},
+	}
+}
+
+func inTriangle(x, y float64, t [3][2]float64) bool {
+	sign := func(a, b [2]float64) float64 {
+		return (x-b[0])*(a[1]-b[1]) - (a[0]-b[0])*(y-b[1])
+	}
+
+	d1, d2, d3 := sign(t[0], t[1]), sign(t[1], t[2]), sign(t[2], t[0])
+	negative := d1 < 0 || d2 < 0 || d3 < 0
+	positive := d1 > 0 || d2 > 0 || d3 > 0
+
+	return !(negative && positive)
+}
+
+// Returns the distance of the point from the line segment.
+func segmentDistance(x, y, x1, y1, x2, y2 float64) float64 {
+	dx, dy := x2-x1, y2-y1
+	if dx == 0 && dy == 0 {
+		return math.Hypot(x-x1, y-y1)
+	}
+
+	t := ((x-x1)*dx + (y-y1)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+
+	return math.Hypot(x-(x1+t*dx), y-(y1+t*dy))
+}
@@ -0,0 +1,172 @@
+package raster
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+func parsePath(t *testing.T, data string) []*sgf.Node {
+	collection, err := sgf.ParseSgf(data)
+	if err != nil {
+		t.Fatalf("ParseSgf(%s) returned error: %s", data, err)
+	}
+
+	return collection.GameTrees[0].MainLine()
+}
+
+func TestImage(t *testing.T) {
+	options := DefaultOptions
+	options.PointSize = 20
+
+	// Center of the point at (x, y)
+	at := func(img image.Image, x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(20+x*20, 20+y*20)).(color.RGBA)
+	}
+
+	img, err := Image(parsePath(t, "(;SZ[5]AB[aa]AW[ba]DD[ee];B[cc]SQ[bb])"), options)
+	if err != nil {
+		t.Fatalf("Image returned error: %s", err)
+	}
+
+	if size := img.Bounds().Size(); size.X != 121 || size.Y != 121 {
+		t.Errorf("Image size mismatch. Got: %v.", size)
+	}
+
+	var tests = []struct {
+		x, y   int
+		wanted color.RGBA
+	}{
+		{0, 0, options.BlackColor},
+		{1, 0, options.WhiteColor},
+		{2, 2, options.BlackColor}, // last move circle is not in the center
+		{3, 3, options.LineColor},
+		{4, 4, blend(options.LineColor, options.BoardColor, options.DimOpacity)},
+	}
+
+	for _, test := range tests {
+		if c := at(img, test.x, test.y); c != test.wanted {
+			t.Errorf("Image point (%d, %d) mismatch. wanted: %v, got: %v.", test.x, test.y, test.wanted, c)
+		}
+	}
+
+	// Board color between the grid lines, square outline on (1, 1)
+	if c := color.RGBAModel.Convert(img.At(30, 30)).(color.RGBA); c != options.BoardColor {
+		t.Errorf("Image board color mismatch. Got: %v.", c)
+	}
+
+	if c := color.RGBAModel.Convert(img.At(40, 35)).(color.RGBA); c != options.MarkupColor {
+		t.Errorf("Image square mismatch. Got: %v.", c)
+	}
+}
+
+func TestImageCrop(t *testing.T) {
+	options := DefaultOptions
+	options.PointSize = 10
+
+	for _, test := range []struct {
+		crop   bool
+		wanted image.Point
+	}{
+		{true, image.Point{41, 31}},
+		{false, image.Point{201, 201}},
+	} {
+		options.Crop = test.crop
+
+		img, err := Image(parsePath(t, "(;VW[aa:cb])"), options)
+		if err != nil {
+			t.Fatalf("Image returned error: %s", err)
+		}
+
+		if size := img.Bounds().Size(); size != test.wanted {
+			t.Errorf("Image(crop %t) size mismatch. wanted: %v, got: %v.", test.crop, test.wanted, size)
+		}
+	}
+}
+
+func TestImageErrors(t *testing.T) {
+	var errTests = []string{
+		"(;SZ[foo])",
+		"(;SZ[5];B[ff])",
+		"(;SZ[5]CR[a])",
+		"(;SZ[5]AR[aa])",
+		"(;SZ[5]LN[aa])",
+		"(;SZ[5]DD[a])",
+		"(;SZ[5]VW[aa:ff])",
+	}
+
+	for _, test := range errTests {
+		if _, err := Image(parsePath(t, test), DefaultOptions); err == nil {
+			t.Errorf("Image(%s) did not return error.", test)
+		}
+	}
+
+	options := DefaultOptions
+	options.PointSize = 2
+	if _, err := Image(parsePath(t, "(;)"), options); err == nil {
+		t.Errorf("Image did not return error with too small point size.")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := PNG(&buffer, parsePath(t, "(;SZ[9]AR[aa:cc]LN[ee:gg]TR[dd]MA[ff]CR[hh]SL[ii];B[ee])"), DefaultOptions); err != nil {
+		t.Fatalf("PNG returned error: %s", err)
+	}
+
+	img, err := png.Decode(&buffer)
+	if err != nil {
+		t.Fatalf("png.Decode returned error: %s", err)
+	}
+
+	if size := img.Bounds().Size(); size.X != 2*24+8*24+1 {
+		t.Errorf("PNG size mismatch. Got: %v.", size)
+	}
+}
+
+func TestGIF(t *testing.T) {
+	var buffer bytes.Buffer
+	gifOptions := GIFOptions{Delay: 50, FinalDelay: 300, LoopCount: -1}
+
+	if err := GIF(&buffer, parsePath(t, "(;SZ[9];B[ee];W[];B[cc]VW[aa:dd])"), DefaultOptions, gifOptions); err != nil {
+		t.Fatalf("GIF returned error: %s", err)
+	}
+
+	animation, err := gif.DecodeAll(&buffer)
+	if err != nil {
+		t.Fatalf("gif.DecodeAll returned error: %s", err)
+	}
+
+	if len(animation.Image) != 4 {
+		t.Fatalf("GIF frame count mismatch. Got: %d.", len(animation.Image))
+	}
+
+	for i, delay := range []int{50, 50, 50, 300} {
+		if animation.Delay[i] != delay {
+			t.Errorf("GIF delay %d mismatch. wanted: %d, got: %d.", i, delay, animation.Delay[i])
+		}
+
+		if animation.Image[i].Bounds() != animation.Image[0].Bounds() {
+			t.Errorf("GIF frame %d size mismatch. Got: %v.", i, animation.Image[i].Bounds())
+		}
+	}
+
+	// Stone of the first move in the second frame
+	x, y := 24+4*24, 24+4*24
+	if c := color.RGBAModel.Convert(animation.Image[1].At(x, y)).(color.RGBA); c != DefaultOptions.BlackColor {
+		t.Errorf("GIF stone color mismatch. Got: %v.", c)
+	}
+
+	if err := GIF(&buffer, []*sgf.Node{}, DefaultOptions, gifOptions); err == nil {
+		t.Errorf("GIF did not return error for empty line.")
+	}
+
+	if err := GIF(&buffer, parsePath(t, "(;SZ[5];B[ff])"), DefaultOptions, gifOptions); err == nil {
+		t.Errorf("GIF did not return error.")
+	}
+}
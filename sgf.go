@@ -3,7 +3,9 @@ package sgf
 import (
 	"bytes"
 	"io/ioutil"
+	"sort"
 	"strings"
+	"unicode/utf8"
 )
 
 //
@@ -132,6 +134,42 @@ func (gameTree *GameTree) RemoveNodeAt(i int) {
 	gameTree.Nodes = append(gameTree.Nodes[:i], gameTree.Nodes[i+1])
 }
 
+// Returns the Nodes of the main line, i.e. the Nodes of this GameTree followed by the main line of the first child
+// GameTree.
+func (gameTree *GameTree) MainLine() []*Node {
+	nodes := []*Node{}
+
+	for gt := gameTree; gt != nil; {
+		nodes = append(nodes, gt.Nodes...)
+
+		if len(gt.GameTrees) == 0 {
+			break
+		}
+
+		gt = gt.GameTrees[0]
+	}
+
+	return nodes
+}
+
+// Returns the Nodes from the first Node of this GameTree to the given Node, following the child GameTrees. Returns
+// nil if the Node is not part of this GameTree.
+func (gameTree *GameTree) PathTo(node *Node) []*Node {
+	for i, n := range gameTree.Nodes {
+		if n == node {
+			return append([]*Node{}, gameTree.Nodes[:i+1]...)
+		}
+	}
+
+	for _, childGameTree := range gameTree.GameTrees {
+		if path := childGameTree.PathTo(node); path != nil {
+			return append(append([]*Node{}, gameTree.Nodes...), path...)
+		}
+	}
+
+	return nil
+}
+
 // returns the index of the given child GameTree in this GameTree or -1 if child GameTree is not present.
 func (gameTree *GameTree) gameTreeIndex(childGameTree *GameTree) int {
 	for i, gt := range gameTree.GameTrees {
@@ -165,6 +203,17 @@ func (node *Node) NewProperty(ident string, values ...string) *Property {
 	return property
 }
 
+// Returns the first Property with the given ident or nil if the Node does not have such Property.
+func (node *Node) Property(ident string) *Property {
+	for _, p := range node.Properties {
+		if p.Ident == ident {
+			return p
+		}
+	}
+
+	return nil
+}
+
 // Removes the given Property from this Node.
 func (node *Node) RemoveProperty(property *Property) {
 	for i, p := range node.Properties {
@@ -264,91 +313,258 @@ func validGameTree(gameTree *GameTree) bool {
 
 // Used to format SGF format.
 type SgfFormat struct {
-	NewLineBetweenGameTrees bool // put each gameTree to its own line
-	NewLineAlsoBetweenNodes bool // also put each node to its own line, only works if NewLineBetweenGameTrees = true
-	IndentationLevel        int  // how many whitespaces are used when indenting
+	NewLineBetweenGameTrees bool     // put each gameTree to its own line
+	NewLineAlsoBetweenNodes bool     // also put each node to its own line, only works if NewLineBetweenGameTrees = true
+	IndentationLevel        int      // how many whitespaces are used when indenting
+	MaxLineWidth            int      // wrap lines between properties and values when they get longer, 0 = no limit
+	PropertyOrder           []string // properties with these idents are written first in this order
+	SortProperties          bool     // write the rest of the properties in alphabetical order
+	MovesPerLine            int      // put this many nodes on each line, root node on its own line, 0 = not used
+	IndentWithTabs          bool     // indent with a tab per level instead of IndentationLevel spaces
+	CRLF                    bool     // use "\r\n" line endings, also inside Text values
 }
 
 var (
-	DefaultSgfFormat    = SgfFormat{true, true, 4}
-	NoNewLinesSgfFormat = SgfFormat{false, false, 0}
+	DefaultSgfFormat    = SgfFormat{NewLineBetweenGameTrees: true, NewLineAlsoBetweenNodes: true, IndentationLevel: 4}
+	NoNewLinesSgfFormat = SgfFormat{}
+
+	// Similar to the output of CGoban: root properties in canonical order, ten moves per line and lines wrapped at 80
+	// characters.
+	CGobanSgfFormat = SgfFormat{
+		NewLineBetweenGameTrees: true,
+		MaxLineWidth:            80,
+		PropertyOrder:           CanonicalPropertyOrder,
+		MovesPerLine:            10,
+	}
+
+	// Root and game info properties in the commonly used order followed by the move properties.
+	CanonicalPropertyOrder = []string{
+		"FF", "GM", "SZ", "CA", "AP", "ST", "RU", "KM", "HA", "TM", "OT",
+		"GN", "EV", "RO", "DT", "PC", "PB", "BR", "BT", "PW", "WR", "WT", "RE",
+		"AN", "SO", "US", "CP", "ON", "GC",
+		"B", "W",
+	}
 )
 
 // Converts the collection to SGF format.
 func (collection *Collection) Sgf(format SgfFormat) string {
-	var buffer bytes.Buffer
+	return collection.SgfWithLayout(format, nil)
+}
 
+// Converts the collection to SGF format. GameTrees, Nodes and Properties found in the layout (see
+// ParseSgfWithLayout) are written using their original formatting, unless they have been modified. Everything else is
+// formatted using the given format. Nil layout formats the whole collection.
+func (collection *Collection) SgfWithLayout(format SgfFormat, layout *Layout) string {
 	if !collection.Valid() {
 		panic("collection is not valid.")
 	}
 
+	w := &sgfWriter{format: format, layout: layout}
+
 	for _, gameTree := range collection.GameTrees {
-		appendGameTree(&buffer, gameTree, format, 0)
+		w.writeGameTree(gameTree, 0)
 	}
 
-	return buffer.String()
+	if layout != nil {
+		w.writeString(layout.trailing)
+	}
+
+	return w.buffer.String()
 }
 
-func appendGameTree(buffer *bytes.Buffer, gameTree *GameTree, format SgfFormat, level int) {
-	// Add newlines between GameTrees if required
-	if format.NewLineBetweenGameTrees && level > 0 {
-		buffer.WriteRune('\n')
+// Writes SGF and keeps track of the current column for line wrapping.
+type sgfWriter struct {
+	buffer bytes.Buffer
+	format SgfFormat
+	layout *Layout
+	column int
+}
 
-		for i := 0; i < level; i++ {
-			for j := 0; j < format.IndentationLevel; j++ {
-				buffer.WriteRune(' ')
-			}
-		}
+func (w *sgfWriter) writeString(s string) {
+	w.buffer.WriteString(s)
+
+	if i := strings.LastIndexAny(s, "\r\n"); i >= 0 {
+		w.column = utf8.RuneCountInString(s[i+1:])
+	} else {
+		w.column += utf8.RuneCountInString(s)
+	}
+}
+
+// Starts a new line indented to the given level. Extra whitespace is added after the indentation.
+func (w *sgfWriter) newLine(level int, extra int) {
+	if w.format.CRLF {
+		w.writeString("\r\n")
+	} else {
+		w.writeString("\n")
+	}
+
+	if w.format.IndentWithTabs {
+		w.writeString(strings.Repeat("\t", level))
+	} else {
+		w.writeString(strings.Repeat(" ", level*w.format.IndentationLevel))
+	}
+
+	w.writeString(strings.Repeat(" ", extra))
+}
+
+// Wraps the line if the text does not fit on the current line.
+func (w *sgfWriter) wrap(text string, level int) {
+	if w.format.MaxLineWidth <= 0 {
+		return
+	}
+
+	width := utf8.RuneCountInString(text)
+	if i := strings.IndexAny(text, "\r\n"); i >= 0 {
+		width = utf8.RuneCountInString(text[:i])
+	}
+
+	if w.column+width > w.format.MaxLineWidth {
+		// Line up with the Nodes of the GameTree, see writeGameTree
+		w.newLine(level, 2)
+	}
+}
+
+func (w *sgfWriter) writeGameTree(gameTree *GameTree, level int) {
+	gameTreeLayout := w.layout.gameTree(gameTree)
+
+	if gameTreeLayout != nil {
+		// Original whitespace before the GameTree
+		w.writeString(gameTreeLayout.space)
+	} else if w.format.NewLineBetweenGameTrees && level > 0 {
+		// Add newlines between GameTrees if required
+		w.newLine(level, 0)
 	}
 
 	// Start of GameTree
-	buffer.WriteRune('(')
+	w.writeString("(")
 
 	for i, node := range gameTree.Nodes {
-		// Add new lines between nodes if required
-		if format.NewLineBetweenGameTrees && format.NewLineAlsoBetweenNodes && i > 0 {
-			buffer.WriteRune('\n')
-
-			for i := 0; i < level; i++ {
-				for j := 0; j < format.IndentationLevel; j++ {
-					buffer.WriteRune(' ')
-				}
-			}
-
+		if space, ok := w.layout.node(node); ok {
+			// Original whitespace before the Node
+			w.writeString(space)
+		} else if w.nodeStartsLine(i, level) {
 			// Add one more whitespace to line up nodes. Otherwise we get lines like:
 			// (;FF[4]
 			// ;SZ[19]
 			// Instead of:
 			// (;FF[4]
 			//  ;SZ[19]
-			buffer.WriteRune(' ')
+			w.newLine(level, 1)
 		}
 
 		// Start of Node
-		buffer.WriteRune(';')
+		w.writeString(";")
 
-		for _, property := range node.Properties {
-			// Property ident
-			buffer.WriteString(property.Ident)
-
-			// Property values
-			for _, value := range property.Values {
-				buffer.WriteRune('[')
-				escaped := strings.Replace(value, "\\", "\\\\", -1)
-				escaped = strings.Replace(escaped, "]", "\\]", -1)
-				buffer.WriteString(escaped)
-				buffer.WriteRune(']')
-			}
+		for _, property := range w.orderedProperties(node) {
+			w.writeProperty(property, level)
 		}
 	}
 
 	// Child GameTrees
 	for _, childGameTree := range gameTree.GameTrees {
-		appendGameTree(buffer, childGameTree, format, level+1)
+		w.writeGameTree(childGameTree, level+1)
 	}
 
 	// End of GameTree
-	buffer.WriteRune(')')
+	if gameTreeLayout != nil {
+		w.writeString(gameTreeLayout.end)
+	}
+
+	w.writeString(")")
+}
+
+// Check if the Node at the given index should start a new line.
+func (w *sgfWriter) nodeStartsLine(i, level int) bool {
+	if !w.format.NewLineBetweenGameTrees || i == 0 {
+		return false
+	}
+
+	if w.format.MovesPerLine > 0 {
+		// Root node is on its own line
+		if level == 0 {
+			return (i-1)%w.format.MovesPerLine == 0
+		}
+
+		return i%w.format.MovesPerLine == 0
+	}
+
+	return w.format.NewLineAlsoBetweenNodes
+}
+
+func (w *sgfWriter) writeProperty(property *Property, level int) {
+	propertyLayout := w.layout.property(property)
+
+	if propertyLayout != nil {
+		w.writeString(propertyLayout.space)
+
+		// Untouched properties are written as they were
+		if !propertyLayout.modified(property) {
+			w.writeString(propertyLayout.raw)
+			return
+		}
+	}
+
+	info := property.Info()
+
+	for i, value := range property.Values {
+		encoded := "[" + encodeValue(info, value) + "]"
+		if w.format.CRLF && info.Value == ValueText {
+			encoded = strings.Replace(encoded, "\n", "\r\n", -1)
+		}
+
+		if i == 0 {
+			// Property ident is kept together with the first value
+			w.wrap(property.Ident+encoded, level)
+			w.writeString(property.Ident)
+		} else {
+			w.wrap(encoded, level)
+		}
+
+		w.writeString(encoded)
+	}
+}
+
+// Returns the properties of the Node in the order given by the format.
+func (w *sgfWriter) orderedProperties(node *Node) []*Property {
+	if len(w.format.PropertyOrder) == 0 && !w.format.SortProperties {
+		return node.Properties
+	}
+
+	rank := func(property *Property) int {
+		for i, ident := range w.format.PropertyOrder {
+			if ident == property.Ident {
+				return i
+			}
+		}
+
+		return len(w.format.PropertyOrder)
+	}
+
+	properties := append([]*Property{}, node.Properties...)
+	sort.SliceStable(properties, func(i, j int) bool {
+		ri, rj := rank(properties[i]), rank(properties[j])
+		if ri != rj || !w.format.SortProperties {
+			return ri < rj
+		}
+
+		return properties[i].Ident < properties[j].Ident
+	})
+
+	return properties
+}
+
+// Encodes the value using the encoder of the value type.
+func encodeValue(info PropertyInfo, value string) string {
+	switch {
+	case info.Composed != ValueUnknown:
+		return encodeComposedValue(info, value)
+	case info.Value == ValueText:
+		return EncodeText(value)
+	case info.Value == ValueSimpleText:
+		return EncodeSimpleText(value)
+	}
+
+	return escapeValue(value)
 }
 
 var readFileFunc func(string) ([]byte, error) = ioutil.ReadFile
@@ -367,10 +583,13 @@ func ParseSgfFile(filename string) (*Collection, error) {
 func ParseSgf(data string) (*Collection, error) {
 	lexemes, err := lexicalAnalysis(data)
 	if err != nil {
-		return nil, err
+		return nil, locateSyntaxError(err, data)
 	}
 
-	collection, err := parse(lexemes)
+	collection, err := parse(lexemes, nil)
+	if err != nil {
+		return nil, locateSyntaxError(err, data)
+	}
 
-	return collection, err
+	return collection, nil
 }
@@ -1,45 +1,283 @@
 package sgf
 
-/*
 import (
+	"bytes"
 	"fmt"
+	"strings"
 )
 
-func GraphCollection(collection *Collection) {
-	for _, gt := range collection.GameTrees {
-		graphGameTree(gt, 0, true)
-	}
-	fmt.Println()
+// Options for variation tree graphs.
+type GraphOptions struct {
+	Current     *Node // highlighted Node, nil for none
+	MoveNumbers bool  // show move numbers
+}
+
+// Node placed on the graph grid. Columns are the depth of the Node and rows the variations.
+type graphNode struct {
+	node   *Node
+	row    int
+	column int
+	parent *graphNode // previous Node, nil for roots
+}
+
+// Content of a grid cell: a Node or a part of the connector from a branching Node to its variations.
+type graphCell struct {
+	node   *graphNode
+	branch *graphNode // branching Node of a connector cell
+	corner bool       // connector turns to a variation
+}
+
+type graphLayout struct {
+	nodes   []*graphNode
+	cells   map[[2]int]*graphCell // by row and column
+	columns []int                 // lowest used row of each column
+	rows    int
+	depths  map[*GameTree]int
 }
 
-func graphGameTree(gameTree *GameTree, indentation int, firstNode bool) {
-	if firstNode {
-		for i := 0; i < indentation; i++ {
-			if i+2 == indentation {
-				fmt.Print("+")
-			} else if i+1 == indentation {
-				fmt.Print("-")
-			} else {
-				fmt.Print(" ")
+// Returns the variation tree of the collection as text. Nodes are drawn as 'o' and the current Node as '@'. The main
+// line of each GameTree is the first row and variations are drawn below their branching Node:
+//
+//	o-o-o-o
+//	|   `-o
+//	`-o-o
+//	  `-o
+//
+// With move numbers the first line shows the number of every tenth column.
+func (collection *Collection) Graph(options GraphOptions) string {
+	layout := newGraphLayout(collection)
+
+	lines := make([][]rune, layout.rows)
+	for row := range lines {
+		lines[row] = []rune(strings.Repeat(" ", 2*len(layout.columns)))
+	}
+
+	for key, cell := range layout.cells {
+		row, column := key[0], key[1]
+
+		switch {
+		case cell.node != nil:
+			lines[row][2*column] = 'o'
+			if cell.node.node == options.Current {
+				lines[row][2*column] = '@'
+			}
+
+			if parent := cell.node.parent; parent != nil && parent.row == row {
+				lines[row][2*column-1] = '-'
 			}
+		case cell.corner && layout.continues(row, column, cell.branch):
+			lines[row][2*column] = '+'
+			lines[row][2*column+1] = '-'
+		case cell.corner:
+			lines[row][2*column] = '`'
+			lines[row][2*column+1] = '-'
+		default:
+			lines[row][2*column] = '|'
 		}
 	}
 
-	for _, _ = range gameTree.Nodes {
-		if !firstNode {
-			fmt.Print("-")
-			indentation += 2
+	var buffer bytes.Buffer
+
+	if options.MoveNumbers {
+		ruler := []rune(strings.Repeat(" ", 2*len(layout.columns)+2))
+		for column := 0; column < len(layout.columns); column += 10 {
+			copy(ruler[2*column:], []rune(fmt.Sprint(layout.moveNumber(column))))
 		}
-		fmt.Print("o")
-		firstNode = false
+
+		buffer.WriteString(strings.TrimRight(string(ruler), " ") + "\n")
+	}
+
+	for _, line := range lines {
+		buffer.WriteString(strings.TrimRight(string(line), " ") + "\n")
+	}
+
+	return buffer.String()
+}
+
+// Size of a grid cell in the SVG graph in pixels.
+const graphCellSize = 24
+
+// Returns the variation tree of the collection as SVG. Moves are drawn with the color of the player, other Nodes are
+// gray and the current Node is circled.
+func (collection *Collection) GraphSVG(options GraphOptions) string {
+	layout := newGraphLayout(collection)
+
+	center := func(row, column int) (int, int) {
+		return column*graphCellSize + graphCellSize/2, row*graphCellSize + graphCellSize/2
 	}
-	for i, childGameTree := range gameTree.GameTrees {
-		if i == 0 {
-			graphGameTree(childGameTree, indentation, firstNode)
+
+	width, height := len(layout.columns)*graphCellSize, layout.rows*graphCellSize
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\">\n",
+		width, height, width, height))
+
+	// Connectors below the Nodes
+	for _, n := range layout.nodes {
+		if n.parent == nil {
+			continue
+		}
+
+		x1, y1 := center(n.parent.row, n.parent.column)
+		x2, y2 := center(n.row, n.column)
+
+		if n.parent.row == n.row {
+			buffer.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#666\"/>\n", x1, y1, x2, y2))
 		} else {
-			fmt.Println()
-			graphGameTree(childGameTree, indentation+2, true)
+			buffer.WriteString(fmt.Sprintf("<polyline points=\"%d,%d %d,%d %d,%d\" fill=\"none\" stroke=\"#666\"/>\n", x1, y1, x1, y2, x2, y2))
+		}
+	}
+
+	for _, n := range layout.nodes {
+		x, y := center(n.row, n.column)
+
+		fill, textColor := "#999", "#000"
+		color, _, _, err := n.node.Move(52, 52)
+		switch {
+		case err != nil:
+		case color == Black:
+			fill, textColor = "#000", "#fff"
+		case color == White:
+			fill = "#fff"
+		}
+
+		if n.node == options.Current {
+			buffer.WriteString(fmt.Sprintf("<circle cx=\"%d\" cy=\"%d\" r=\"10\" fill=\"none\" stroke=\"#e00\" stroke-width=\"2\"/>\n", x, y))
+		}
+
+		buffer.WriteString(fmt.Sprintf("<circle cx=\"%d\" cy=\"%d\" r=\"7\" fill=\"%s\" stroke=\"#000\"/>\n", x, y, fill))
+
+		if options.MoveNumbers && color != Empty {
+			buffer.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-size=\"7\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%d</text>\n",
+				x, y, textColor, layout.nodeMoveNumber(n)))
+		}
+	}
+
+	buffer.WriteString("</svg>\n")
+
+	return buffer.String()
+}
+
+// Places the Nodes of the collection on the grid. GameTrees of the collection are placed below each other. Each
+// variation is placed on the first row below everything placed earlier in the columns the variation and its subtree
+// use, so that the connectors never cross other branches.
+func newGraphLayout(collection *Collection) *graphLayout {
+	layout := &graphLayout{cells: map[[2]int]*graphCell{}, depths: map[*GameTree]int{}}
+
+	for _, gameTree := range collection.GameTrees {
+		layout.addGameTree(gameTree, nil, layout.rows)
+	}
+
+	return layout
+}
+
+func (layout *graphLayout) addGameTree(gameTree *GameTree, parent *graphNode, row int) {
+	column := 0
+	if parent != nil {
+		column = parent.column + 1
+	}
+
+	for _, node := range gameTree.Nodes {
+		n := &graphNode{node, row, column, parent}
+		layout.nodes = append(layout.nodes, n)
+		layout.set(row, column, &graphCell{node: n})
+
+		parent = n
+		column++
+	}
+
+	if len(gameTree.GameTrees) == 0 {
+		return
+	}
+
+	layout.addGameTree(gameTree.GameTrees[0], parent, row)
+
+	for _, variation := range gameTree.GameTrees[1:] {
+		variationRow := layout.freeRow(parent.column, parent.column+1+layout.depth(variation))
+
+		for r := parent.row + 1; r < variationRow; r++ {
+			if layout.cells[[2]int{r, parent.column}] == nil {
+				layout.set(r, parent.column, &graphCell{branch: parent})
+			}
 		}
+
+		layout.set(variationRow, parent.column, &graphCell{branch: parent, corner: true})
+		layout.addGameTree(variation, parent, variationRow)
+	}
+}
+
+func (layout *graphLayout) set(row, column int, cell *graphCell) {
+	layout.cells[[2]int{row, column}] = cell
+
+	for len(layout.columns) <= column {
+		layout.columns = append(layout.columns, -1)
+	}
+
+	if row > layout.columns[column] {
+		layout.columns[column] = row
+	}
+
+	if row >= layout.rows {
+		layout.rows = row + 1
 	}
 }
-*/
+
+// Returns the first row below all the used cells between the columns.
+func (layout *graphLayout) freeRow(first, last int) int {
+	row := 0
+
+	for column := first; column < last && column < len(layout.columns); column++ {
+		if layout.columns[column] >= row {
+			row = layout.columns[column] + 1
+		}
+	}
+
+	return row
+}
+
+// Returns the number of columns used by the GameTree and its variations.
+func (layout *graphLayout) depth(gameTree *GameTree) int {
+	if depth, ok := layout.depths[gameTree]; ok {
+		return depth
+	}
+
+	depth := 0
+	for _, child := range gameTree.GameTrees {
+		if d := layout.depth(child); d > depth {
+			depth = d
+		}
+	}
+
+	depth += len(gameTree.Nodes)
+	layout.depths[gameTree] = depth
+
+	return depth
+}
+
+// Check if the connector of the branching Node continues below the row.
+func (layout *graphLayout) continues(row, column int, branch *graphNode) bool {
+	cell := layout.cells[[2]int{row + 1, column}]
+	return cell != nil && cell.branch == branch
+}
+
+// Returns the move number of the Node: the number of moves from the root to the Node.
+func (layout *graphLayout) nodeMoveNumber(n *graphNode) int {
+	number := 0
+
+	for ; n != nil; n = n.parent {
+		if color, _, _, _ := n.node.Move(52, 52); color != Empty {
+			number++
+		}
+	}
+
+	return number
+}
+
+// Returns the move number of the first row Node in the column, or the column if the first row does not reach it.
+func (layout *graphLayout) moveNumber(column int) int {
+	if cell := layout.cells[[2]int{0, column}]; cell != nil && cell.node != nil {
+		return layout.nodeMoveNumber(cell.node)
+	}
+
+	return column
+}
@@ -0,0 +1,90 @@
+package sgf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	var tests = []struct {
+		data        string
+		moveNumbers bool
+		wanted      string
+	}{
+		{"(;)", false, "o\n"},
+		{"(;;;)(;;)", false, "o-o-o\no-o\n"},
+		{"(;B[aa](;W[bb];B[cc](;W[dd])(;W[ee]))(;W[ff](;B[gg])(;B[hh])))", false,
+			"o-o-o-o\n" +
+				"|   `-o\n" +
+				"`-o-o\n" +
+				"  `-o\n"},
+		// Variations of the same Node share the connector
+		{"(;(;;)(;)(;;;))", false,
+			"o-o-o\n" +
+				"+-o\n" +
+				"`-o-o-o\n"},
+		// Long variation is placed below the deeper branches
+		{"(;;;;(;;;;;(;;)(;))(;;;;;;;;)(;))", false,
+			"o-o-o-o-o-o-o-o-o-o-o\n" +
+				"      |         `-o\n" +
+				"      +-o-o-o-o-o-o-o-o\n" +
+				"      `-o\n"},
+		{"(;B[aa];W[ab];B[ac];W[ad];B[ae];W[af];B[ag];W[ah];B[ai];W[aj];B[ak];W[al](;B[am])(;B[an]))", true,
+			"1                   11\n" +
+				"o-o-o-o-o-o-o-o-o-o-o-o-o\n" +
+				"                      `-o\n"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.data, err)
+		}
+
+		if graph := collection.Graph(GraphOptions{MoveNumbers: test.moveNumbers}); graph != test.wanted {
+			t.Errorf("Graph(%s) mismatch. Got:\n%s", test.data, graph)
+		}
+	}
+}
+
+func TestGraphCurrent(t *testing.T) {
+	collection, err := ParseSgf("(;(;;)(;))")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	current := collection.GameTrees[0].GameTrees[1].Nodes[0]
+	wanted := "o-o-o\n`-@\n"
+
+	if graph := collection.Graph(GraphOptions{Current: current}); graph != wanted {
+		t.Errorf("Graph mismatch. Got:\n%s", graph)
+	}
+}
+
+func TestGraphSVG(t *testing.T) {
+	collection, err := ParseSgf("(;B[aa](;W[bb])(;W[cc]))")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	wanted := "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"48\" height=\"48\" viewBox=\"0 0 48 48\" font-family=\"sans-serif\">\n" +
+		"<line x1=\"12\" y1=\"12\" x2=\"36\" y2=\"12\" stroke=\"#666\"/>\n" +
+		"<polyline points=\"12,12 12,36 36,36\" fill=\"none\" stroke=\"#666\"/>\n" +
+		"<circle cx=\"12\" cy=\"12\" r=\"7\" fill=\"#000\" stroke=\"#000\"/>\n" +
+		"<text x=\"12\" y=\"12\" font-size=\"7\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"#fff\">1</text>\n" +
+		"<circle cx=\"36\" cy=\"12\" r=\"7\" fill=\"#fff\" stroke=\"#000\"/>\n" +
+		"<text x=\"36\" y=\"12\" font-size=\"7\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"#000\">2</text>\n" +
+		"<circle cx=\"36\" cy=\"36\" r=\"10\" fill=\"none\" stroke=\"#e00\" stroke-width=\"2\"/>\n" +
+		"<circle cx=\"36\" cy=\"36\" r=\"7\" fill=\"#fff\" stroke=\"#000\"/>\n" +
+		"<text x=\"36\" y=\"36\" font-size=\"7\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"#000\">2</text>\n" +
+		"</svg>\n"
+
+	options := GraphOptions{Current: collection.GameTrees[0].GameTrees[1].Nodes[0], MoveNumbers: true}
+	if svg := collection.GraphSVG(options); svg != wanted {
+		t.Errorf("GraphSVG mismatch. Got:\n%s", svg)
+	}
+
+	if svg := collection.GraphSVG(GraphOptions{}); strings.Contains(svg, "<text") || strings.Contains(svg, "#e00") {
+		t.Errorf("GraphSVG contains move numbers or current Node. Got:\n%s", svg)
+	}
+}
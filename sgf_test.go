@@ -85,6 +85,46 @@ func TestSgf(t *testing.T) {
 	}
 }
 
+func TestSgfFormatOptions(t *testing.T) {
+	var tests = []struct {
+		data   string
+		wanted string
+		format SgfFormat
+	}{
+		// line width
+		{"(;FF[4]GM[1]AB[aa][bb][cc][dd];B[ee])",
+			"(;FF[4]GM[1]\n  AB[aa][bb]\n  [cc][dd]\n ;B[ee])",
+			SgfFormat{NewLineBetweenGameTrees: true, NewLineAlsoBetweenNodes: true, MaxLineWidth: 12}},
+		// property order
+		{"(;PB[b]SZ[19]C[c]FF[4]AB[aa];W[bb]C[c]B[aa])",
+			"(;FF[4]SZ[19]PB[b]AB[aa]C[c];B[aa]W[bb]C[c])",
+			SgfFormat{PropertyOrder: CanonicalPropertyOrder, SortProperties: true}},
+		{"(;C[c]AB[aa]FF[4])",
+			"(;FF[4]C[c]AB[aa])",
+			SgfFormat{PropertyOrder: CanonicalPropertyOrder}},
+		// moves per line
+		{"(;FF[4];B[aa];W[bb];B[cc];W[dd];B[ee](;W[ff];B[gg];W[hh]))",
+			"(;FF[4]\n ;B[aa];W[bb]\n ;B[cc];W[dd]\n ;B[ee]\n (;W[ff];B[gg]\n  ;W[hh]))",
+			SgfFormat{NewLineBetweenGameTrees: true, MovesPerLine: 2, IndentationLevel: 1}},
+		// tabs and CRLF
+		{"(;FF[4]C[a\nb](;B[aa];W[bb]))",
+			"(;FF[4]C[a\r\nb]\r\n\t(;B[aa]\r\n\t ;W[bb]))",
+			SgfFormat{NewLineBetweenGameTrees: true, NewLineAlsoBetweenNodes: true, IndentWithTabs: true, CRLF: true}},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Errorf("ParseSgf(%s) returned error.", test.data)
+			continue
+		}
+
+		if sgf := collection.Sgf(test.format); sgf != test.wanted {
+			t.Errorf("collection.Sgf(%s) mismatch. wanted: %q, got: %q.", test.data, test.wanted, sgf)
+		}
+	}
+}
+
 func TestSgfPanics(t *testing.T) {
 	c := Collection{}
 
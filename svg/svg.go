@@ -0,0 +1,364 @@
+/*
+Package svg draws board positions of sgf game records as SVG images.
+
+The image shows the position after the last Node of the given path (see sgf.GameTree.PathTo) with the grid, hoshi
+points, stones, move numbers of the current figure and all the markup of the Node: shapes (CR, SQ, TR, MA, SL),
+labels (LB), arrows (AR) and lines (LN). Points dimmed by DD are faded and the visible area (VW) can be used to crop
+the image. Figure flags of the FG property override the coordinate and hoshi options.
+*/
+package svg
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+
+	"github.com/toikarin/sgf"
+)
+
+// Options for drawing the images. Colors are any SVG color values.
+type Options struct {
+	PointSize   float64 // distance of the grid lines in pixels
+	Coordinates bool    // draw column letters and row numbers
+	Hoshi       bool    // draw hoshi points
+	MoveNumbers bool    // draw move numbers on the stones
+	FirstMove   int     // first numbered move, 0 = start of the current figure
+	Crop        bool    // draw only the visible area (VW) in effect
+	FontFamily  string
+	BoardColor  string
+	LineColor   string
+	BlackColor  string
+	WhiteColor  string
+	MarkupColor string // color of markup on empty points and white stones
+	DimOpacity  float64
+}
+
+var (
+	// Default options.
+	DefaultOptions = Options{
+		PointSize:   24,
+		Coordinates: true,
+		Hoshi:       true,
+		MoveNumbers: true,
+		Crop:        true,
+		FontFamily:  "sans-serif",
+		BoardColor:  "#dcb35c",
+		LineColor:   "#000000",
+		BlackColor:  "#000000",
+		WhiteColor:  "#ffffff",
+		MarkupColor: "#000000",
+		DimOpacity:  0.6,
+	}
+)
+
+// Drawing state of a single image.
+type drawer struct {
+	buffer  bytes.Buffer
+	options Options
+	board   *sgf.Board
+	x1, y1  int     // upper left corner of the drawn area
+	x2, y2  int     // lower right corner of the drawn area
+	margin  float64 // space around the grid
+}
+
+// Draws the position after the last Node of the path as SVG.
+func Render(path []*sgf.Node, options Options) (string, error) {
+	board, err := sgf.Position(path)
+	if err != nil {
+		return "", err
+	}
+
+	if flags, _, ok := sgf.Figure(path); ok {
+		options.Coordinates = flags&sgf.FigureCoordinates != 0
+		options.Hoshi = flags&sgf.FigureHoshi != 0
+	}
+
+	d := &drawer{options: options, board: board, x2: board.Width - 1, y2: board.Height - 1}
+
+	if options.Crop {
+		topLeft, bottomRight, err := sgf.ViewRectangle(path, board.Width, board.Height)
+		if err != nil {
+			return "", err
+		}
+
+		d.x1, d.y1, d.x2, d.y2 = topLeft.X, topLeft.Y, bottomRight.X, bottomRight.Y
+	}
+
+	d.margin = options.PointSize
+	if options.Coordinates {
+		d.margin = options.PointSize * 1.5
+	}
+
+	node := path[len(path)-1]
+
+	numbers := map[sgf.Point]int{}
+	if options.MoveNumbers {
+		if numbers, err = sgf.MoveNumbers(path, options.FirstMove); err != nil {
+			return "", err
+		}
+	}
+
+	shapes, err := node.Shapes()
+	if err != nil {
+		return "", err
+	}
+
+	labels, err := node.Labels()
+	if err != nil {
+		return "", err
+	}
+
+	arrows, err := node.Arrows()
+	if err != nil {
+		return "", err
+	}
+
+	lines, err := node.Lines()
+	if err != nil {
+		return "", err
+	}
+
+	dimmed, err := sgf.InheritedDimmed(path)
+	if err != nil {
+		return "", err
+	}
+
+	width := 2*d.margin + float64(d.x2-d.x1)*options.PointSize
+	height := 2*d.margin + float64(d.y2-d.y1)*options.PointSize
+
+	d.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\" font-family=\"%s\">\n",
+		n(width), n(height), n(width), n(height), html.EscapeString(options.FontFamily))
+	d.printf("<rect width=\"%s\" height=\"%s\" fill=\"%s\"/>\n", n(width), n(height), options.BoardColor)
+
+	d.drawGrid()
+
+	if options.Coordinates {
+		d.drawCoordinates()
+	}
+
+	d.drawStones()
+
+	// Shapes replace the move numbers and labels replace both
+	texts := map[sgf.Point]string{}
+	for point, number := range numbers {
+		if shape := shapes[point]; shape == sgf.ShapeNone || shape == sgf.ShapeSelected {
+			texts[point] = fmt.Sprint(number)
+		}
+	}
+
+	for _, label := range labels {
+		texts[label.Point] = label.Text
+	}
+
+	for y := d.y1; y <= d.y2; y++ {
+		for x := d.x1; x <= d.x2; x++ {
+			point := sgf.Point{X: x, Y: y}
+			if _, ok := texts[point]; !ok || shapes[point] == sgf.ShapeSelected {
+				d.drawShape(point, shapes[point])
+			}
+		}
+	}
+
+	d.drawTexts(texts)
+
+	for _, line := range lines {
+		d.drawLine(line, false)
+	}
+
+	for _, arrow := range arrows {
+		d.drawLine(arrow, true)
+	}
+
+	for _, point := range dimmed {
+		if d.visible(point) {
+			d.printf("<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\" fill-opacity=\"%s\"/>\n",
+				n(d.cx(point.X)-options.PointSize/2), n(d.cy(point.Y)-options.PointSize/2), n(options.PointSize),
+				n(options.PointSize), options.BoardColor, n(options.DimOpacity))
+		}
+	}
+
+	d.printf("</svg>\n")
+
+	return d.buffer.String(), nil
+}
+
+func (d *drawer) printf(format string, args ...interface{}) {
+	d.buffer.WriteString(fmt.Sprintf(format, args...))
+}
+
+// Returns the x coordinate of the center of the column.
+func (d *drawer) cx(x int) float64 {
+	return d.margin + float64(x-d.x1)*d.options.PointSize
+}
+
+// Returns the y coordinate of the center of the row.
+func (d *drawer) cy(y int) float64 {
+	return d.margin + float64(y-d.y1)*d.options.PointSize
+}
+
+func (d *drawer) visible(point sgf.Point) bool {
+	return point.X >= d.x1 && point.X <= d.x2 && point.Y >= d.y1 && point.Y <= d.y2
+}
+
+func (d *drawer) drawGrid() {
+	s := d.options.PointSize
+
+	// Lines continue half a point over the edges of the cropped area
+	extend := func(c, edge int) float64 {
+		if c == edge {
+			return 0
+		}
+
+		return s / 2
+	}
+
+	left, right := d.cx(d.x1)-extend(d.x1, 0), d.cx(d.x2)+extend(d.x2, d.board.Width-1)
+	top, bottom := d.cy(d.y1)-extend(d.y1, 0), d.cy(d.y2)+extend(d.y2, d.board.Height-1)
+
+	for y := d.y1; y <= d.y2; y++ {
+		d.printf("<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\"/>\n", n(left), n(d.cy(y)), n(right), n(d.cy(y)), d.options.LineColor)
+	}
+
+	for x := d.x1; x <= d.x2; x++ {
+		d.printf("<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\"/>\n", n(d.cx(x)), n(top), n(d.cx(x)), n(bottom), d.options.LineColor)
+	}
+
+	if d.options.Hoshi {
+		for _, point := range sgf.StarPoints(d.board.Width, d.board.Height) {
+			if d.visible(point) {
+				d.printf("<circle cx=\"%s\" cy=\"%s\" r=\"%s\" fill=\"%s\"/>\n", n(d.cx(point.X)), n(d.cy(point.Y)), n(s*0.1), d.options.LineColor)
+			}
+		}
+	}
+}
+
+func (d *drawer) drawCoordinates() {
+	s := d.options.PointSize
+
+	for x := d.x1; x <= d.x2; x++ {
+		d.printf("<text x=\"%s\" y=\"%s\" font-size=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%s</text>\n",
+			n(d.cx(x)), n(d.margin-s), n(s*0.5), d.options.LineColor, sgf.ColumnLabel(x, d.board.Width))
+	}
+
+	for y := d.y1; y <= d.y2; y++ {
+		d.printf("<text x=\"%s\" y=\"%s\" font-size=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%d</text>\n",
+			n(d.margin-s), n(d.cy(y)), n(s*0.5), d.options.LineColor, d.board.Height-y)
+	}
+}
+
+func (d *drawer) drawStones() {
+	for y := d.y1; y <= d.y2; y++ {
+		for x := d.x1; x <= d.x2; x++ {
+			var fill string
+			switch d.board.At(sgf.Point{X: x, Y: y}) {
+			case sgf.Black:
+				fill = d.options.BlackColor
+			case sgf.White:
+				fill = d.options.WhiteColor
+			default:
+				continue
+			}
+
+			d.printf("<circle cx=\"%s\" cy=\"%s\" r=\"%s\" fill=\"%s\" stroke=\"%s\"/>\n",
+				n(d.cx(x)), n(d.cy(y)), n(d.options.PointSize*0.48), fill, d.options.LineColor)
+		}
+	}
+}
+
+// Returns the color of the markup drawn on the point.
+func (d *drawer) markupColor(point sgf.Point) string {
+	if d.board.At(point) == sgf.Black {
+		return d.options.WhiteColor
+	}
+
+	return d.options.MarkupColor
+}
+
+func (d *drawer) drawShape(point sgf.Point, shape sgf.Shape) {
+	x, y, s := d.cx(point.X), d.cy(point.Y), d.options.PointSize
+	stroke := fmt.Sprintf("fill=\"none\" stroke=\"%s\" stroke-width=\"%s\"", d.markupColor(point), n(s*0.08))
+
+	switch shape {
+	case sgf.ShapeCircle:
+		d.printf("<circle cx=\"%s\" cy=\"%s\" r=\"%s\" %s/>\n", n(x), n(y), n(s*0.25), stroke)
+	case sgf.ShapeSquare:
+		d.printf("<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" %s/>\n", n(x-s*0.22), n(y-s*0.22), n(s*0.44), n(s*0.44), stroke)
+	case sgf.ShapeTriangle:
+		r := s * 0.3
+		d.printf("<polygon points=\"%s,%s %s,%s %s,%s\" %s/>\n",
+			n(x), n(y-r), n(x-r*math.Sqrt(3)/2), n(y+r/2), n(x+r*math.Sqrt(3)/2), n(y+r/2), stroke)
+	case sgf.ShapeCross:
+		r := s * 0.2
+		d.printf("<path d=\"M%s %sL%s %sM%s %sL%s %s\" %s/>\n", n(x-r), n(y-r), n(x+r), n(y+r), n(x-r), n(y+r), n(x+r), n(y-r), stroke)
+	case sgf.ShapeSelected:
+		d.printf("<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\" fill-opacity=\"0.4\"/>\n",
+			n(x-s/2), n(y-s/2), n(s), n(s), d.markupColor(point))
+	}
+}
+
+// Draws labels and move numbers. Grid is hidden under the texts on empty points.
+func (d *drawer) drawTexts(texts map[sgf.Point]string) {
+	for y := d.y1; y <= d.y2; y++ {
+		for x := d.x1; x <= d.x2; x++ {
+			point := sgf.Point{X: x, Y: y}
+
+			text, ok := texts[point]
+			if !ok {
+				continue
+			}
+
+			size := d.options.PointSize * 0.55
+			if len(text) > 2 {
+				size = d.options.PointSize * 0.4
+			}
+
+			if d.board.At(point) == sgf.Empty {
+				d.printf("<circle cx=\"%s\" cy=\"%s\" r=\"%s\" fill=\"%s\"/>\n", n(d.cx(x)), n(d.cy(y)), n(d.options.PointSize*0.4), d.options.BoardColor)
+			}
+
+			d.printf("<text x=\"%s\" y=\"%s\" font-size=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%s</text>\n",
+				n(d.cx(x)), n(d.cy(y)), n(size), d.markupColor(point), html.EscapeString(text))
+		}
+	}
+}
+
+func (d *drawer) drawLine(line sgf.Line, arrow bool) {
+	x1, y1, x2, y2 := d.cx(line.From.X), d.cy(line.From.Y), d.cx(line.To.X), d.cy(line.To.Y)
+	stroke := fmt.Sprintf("stroke=\"%s\" stroke-width=\"%s\"", d.options.MarkupColor, n(d.options.PointSize*0.08))
+
+	d.printf("<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" %s/>\n", n(x1), n(y1), n(x2), n(y2), stroke)
+
+	if !arrow || (x1 == x2 && y1 == y2) {
+		return
+	}
+
+	// Arrow head at the end point
+	angle := math.Atan2(y2-y1, x2-x1)
+	size := d.options.PointSize * 0.35
+
+	d.printf("<polygon points=\"%s,%s %s,%s %s,%s\" fill=\"%s\"/>\n",
+		n(x2), n(y2),
+		n(x2-size*math.Cos(angle-math.Pi/7)), n(y2-size*math.Sin(angle-math.Pi/7)),
+		n(x2-size*math.Cos(angle+math.Pi/7)), n(y2-size*math.Sin(angle+math.Pi/7)),
+		d.options.MarkupColor)
+}
+
+// Formats the number with at most two decimals.
+func n(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
@@ -0,0 +1,154 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+var testOptions = Options{
+	PointSize:   10,
+	MoveNumbers: true,
+	FontFamily:  "serif",
+	BoardColor:  "#fff",
+	LineColor:   "#000",
+	BlackColor:  "#000",
+	WhiteColor:  "#fff",
+	MarkupColor: "#000",
+	DimOpacity:  0.5,
+}
+
+func TestRender(t *testing.T) {
+	collection, err := sgf.ParseSgf("(;SZ[3];B[bb]LB[aa:A])")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	wanted := "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"40\" height=\"40\" viewBox=\"0 0 40 40\" font-family=\"serif\">\n" +
+		"<rect width=\"40\" height=\"40\" fill=\"#fff\"/>\n" +
+		"<line x1=\"10\" y1=\"10\" x2=\"30\" y2=\"10\" stroke=\"#000\"/>\n" +
+		"<line x1=\"10\" y1=\"20\" x2=\"30\" y2=\"20\" stroke=\"#000\"/>\n" +
+		"<line x1=\"10\" y1=\"30\" x2=\"30\" y2=\"30\" stroke=\"#000\"/>\n" +
+		"<line x1=\"10\" y1=\"10\" x2=\"10\" y2=\"30\" stroke=\"#000\"/>\n" +
+		"<line x1=\"20\" y1=\"10\" x2=\"20\" y2=\"30\" stroke=\"#000\"/>\n" +
+		"<line x1=\"30\" y1=\"10\" x2=\"30\" y2=\"30\" stroke=\"#000\"/>\n" +
+		"<circle cx=\"20\" cy=\"20\" r=\"4.8\" fill=\"#000\" stroke=\"#000\"/>\n" +
+		"<circle cx=\"10\" cy=\"10\" r=\"4\" fill=\"#fff\"/>\n" +
+		"<text x=\"10\" y=\"10\" font-size=\"5.5\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"#000\">A</text>\n" +
+		"<text x=\"20\" y=\"20\" font-size=\"5.5\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"#fff\">1</text>\n" +
+		"</svg>\n"
+
+	svg, err := Render(collection.GameTrees[0].MainLine(), testOptions)
+	if err != nil {
+		t.Fatalf("Render returned error: %s", err)
+	}
+
+	if svg != wanted {
+		t.Errorf("Render mismatch. Got:\n%s", svg)
+	}
+}
+
+func TestRenderContents(t *testing.T) {
+	var tests = []struct {
+		data     string
+		options  func(*Options)
+		wanted   []string
+		unwanted []string
+	}{
+		// Move numbers of the current figure
+		{"(;SZ[5];B[aa];W[bb]FG[];B[cc])", nil,
+			[]string{">2</text>", ">3</text>"}, []string{">1</text>"}},
+		{"(;SZ[5];B[aa];W[bb];B[cc])", func(o *Options) { o.FirstMove = 3 },
+			[]string{">3</text>"}, []string{">1</text>", ">2</text>"}},
+		{"(;SZ[5];B[aa];W[bb];B[cc])", func(o *Options) { o.MoveNumbers = false },
+			nil, []string{"</text>"}},
+		// Shapes hide the move numbers
+		{"(;SZ[5];B[aa];W[bb]TR[bb]SQ[cc]CR[dd]MA[ee]SL[ab])", nil,
+			[]string{"<polygon points=\"20,17 17.4,21.5 22.6,21.5\"", "<rect x=\"27.8\" y=\"27.8\"", "<circle cx=\"40\" cy=\"40\" r=\"2.5\"",
+				"<path d=\"M48 48L52 52M48 52L52 48\"", "fill-opacity=\"0.4\"", ">1</text>"},
+			[]string{">2</text>"}},
+		// Labels are escaped
+		{"(;SZ[5]LB[aa:<b>])", nil, []string{">&lt;b&gt;</text>"}, nil},
+		// Arrows and lines
+		{"(;SZ[5]AR[aa:cc]LN[ee:ae])", nil,
+			[]string{"<line x1=\"10\" y1=\"10\" x2=\"30\" y2=\"30\" stroke=\"#000\" stroke-width=\"0.8\"/>",
+				"<line x1=\"50\" y1=\"50\" x2=\"10\" y2=\"50\" stroke=\"#000\" stroke-width=\"0.8\"/>", "<polygon points=\"30,30 "},
+			nil},
+		// Dimmed points are inherited
+		{"(;SZ[5]DD[aa:ba];B[cc])", nil,
+			[]string{"<rect x=\"5\" y=\"5\" width=\"10\" height=\"10\" fill=\"#fff\" fill-opacity=\"0.5\"/>",
+				"<rect x=\"15\" y=\"5\" width=\"10\" height=\"10\" fill=\"#fff\" fill-opacity=\"0.5\"/>"},
+			nil},
+		{"(;SZ[5]DD[aa:ba];DD[])", nil, nil, []string{"fill-opacity"}},
+		// View crop, lines continue over the cropped edges
+		{"(;SZ[9]VW[aa:bb])", func(o *Options) { o.Crop = true },
+			[]string{"width=\"30\" height=\"30\"", "<line x1=\"10\" y1=\"10\" x2=\"25\" y2=\"10\""}, nil},
+		{"(;SZ[9]VW[aa:bb])", nil, []string{"width=\"100\" height=\"100\""}, nil},
+		// Coordinates and hoshi, overridden by the figure flags
+		{"(;SZ[9])", func(o *Options) { o.Coordinates = true; o.Hoshi = true },
+			[]string{">A</text>", ">J</text>", ">9</text>", "r=\"1\""}, nil},
+		{"(;SZ[9]FG[0:Figure])", func(o *Options) { o.Coordinates = true; o.Hoshi = true },
+			nil, []string{"</text>", "r=\"1\""}},
+		{"(;SZ[9]FG[513:Figure])", nil, []string{">A</text>", "r=\"1\""}, nil},
+		{"(;SZ[9]FG[32768:Figure])", func(o *Options) { o.Coordinates = true; o.Hoshi = true },
+			[]string{">A</text>", "r=\"1\""}, nil},
+	}
+
+	for _, test := range tests {
+		collection, err := sgf.ParseSgf(test.data)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.data, err)
+		}
+
+		options := testOptions
+		if test.options != nil {
+			test.options(&options)
+		}
+
+		svg, err := Render(collection.GameTrees[0].MainLine(), options)
+		if err != nil {
+			t.Errorf("Render(%s) returned error: %s", test.data, err)
+			continue
+		}
+
+		for _, s := range test.wanted {
+			if !strings.Contains(svg, s) {
+				t.Errorf("Render(%s) does not contain %s. Got:\n%s", test.data, s, svg)
+			}
+		}
+
+		for _, s := range test.unwanted {
+			if strings.Contains(svg, s) {
+				t.Errorf("Render(%s) contains %s. Got:\n%s", test.data, s, svg)
+			}
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	var errTests = []string{
+		"(;SZ[foo])",
+		"(;SZ[5];B[ff])",
+		"(;SZ[5]CR[a])",
+		"(;SZ[5]LB[aa])",
+		"(;SZ[5]AR[aa])",
+		"(;SZ[5]LN[aa])",
+		"(;SZ[5]DD[a])",
+		"(;SZ[5]VW[aa:ff])",
+	}
+
+	options := testOptions
+	options.Crop = true
+
+	for _, test := range errTests {
+		collection, err := sgf.ParseSgf(test)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test, err)
+		}
+
+		if _, err := Render(collection.GameTrees[0].MainLine(), options); err == nil {
+			t.Errorf("Render(%s) did not return error.", test)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Symmetry is one of the 8 symmetries of the board: rotations and mirrorings.
+type Symmetry int
+
+const (
+	SymmetryIdentity      Symmetry = iota
+	SymmetryRotate90               // rotate clockwise by 90 degrees
+	SymmetryRotate180              // rotate by 180 degrees
+	SymmetryRotate270              // rotate clockwise by 270 degrees
+	SymmetryMirror                 // mirror left to right
+	SymmetryFlip                   // mirror top to bottom
+	SymmetryTranspose              // mirror along the diagonal from the upper left to the lower right corner
+	SymmetryAntiTranspose          // mirror along the diagonal from the upper right to the lower left corner
+)
+
+// All symmetries, in the order of preference when several give the same result.
+var symmetries = []Symmetry{SymmetryIdentity, SymmetryRotate90, SymmetryRotate180, SymmetryRotate270, SymmetryMirror,
+	SymmetryFlip, SymmetryTranspose, SymmetryAntiTranspose}
+
+// Names of the symmetries, indexed by Symmetry.
+var symmetryNames = []string{"identity", "rotate90", "rotate180", "rotate270", "mirror", "flip", "transpose",
+	"antitranspose"}
+
+func (symmetry Symmetry) String() string {
+	return symmetryNames[symmetry]
+}
+
+// Returns the symmetries of a board of the given size. Rectangular boards have only the four symmetries which keep
+// the width and the height of the board.
+func Symmetries(width, height int) []Symmetry {
+	valid := []Symmetry{}
+	for _, symmetry := range symmetries {
+		if symmetry.Valid(width, height) {
+			valid = append(valid, symmetry)
+		}
+	}
+
+	return valid
+}
+
+// Returns true if the symmetry maps a board of the given size to itself. Symmetries swapping the axes are valid only
+// on square boards.
+func (symmetry Symmetry) Valid(width, height int) bool {
+	return width == height || !symmetry.swapsAxes()
+}
+
+func (symmetry Symmetry) swapsAxes() bool {
+	switch symmetry {
+	case SymmetryRotate90, SymmetryRotate270, SymmetryTranspose, SymmetryAntiTranspose:
+		return true
+	}
+
+	return false
+}
+
+// Returns the point moved by the symmetry on a board of the given size. Symmetries swapping the axes move the point
+// to a board with the width and the height swapped.
+func (symmetry Symmetry) Point(point Point, width, height int) Point {
+	x, y := point.X, point.Y
+	right, bottom := width-1-x, height-1-y
+
+	switch symmetry {
+	case SymmetryRotate90:
+		return Point{height - 1 - y, x}
+	case SymmetryRotate180:
+		return Point{right, bottom}
+	case SymmetryRotate270:
+		return Point{y, right}
+	case SymmetryMirror:
+		return Point{right, y}
+	case SymmetryFlip:
+		return Point{x, bottom}
+	case SymmetryTranspose:
+		return Point{y, x}
+	case SymmetryAntiTranspose:
+		return Point{height - 1 - y, right}
+	}
+
+	return point
+}
+
+// Returns the symmetry that moves the sequence of points closest to the upper right corner: the first point that
+// differs between the symmetries is the topmost, and of those the rightmost. Symmetries giving the same points are
+// resolved by the order of the Symmetry constants.
+func normalizingSymmetry(points []Point, width, height int) Symmetry {
+	best := SymmetryIdentity
+
+	for _, symmetry := range Symmetries(width, height) {
+		for _, point := range points {
+			a, b := symmetry.Point(point, width, height), best.Point(point, width, height)
+			if a != b {
+				if a.Y < b.Y || (a.Y == b.Y && a.X > b.X) {
+					best = symmetry
+				}
+
+				break
+			}
+		}
+	}
+
+	return best
+}
+
+// Returns the symmetry that brings the opening of the game to the canonical corner: the first move of the main line,
+// or the first move breaking a tie, is moved as close to the upper right corner as possible (topmost, then
+// rightmost). Board size is read from the first Node. Returns SymmetryIdentity if the game has no moves.
+func CanonicalSymmetry(gameTree *GameTree) Symmetry {
+	if len(gameTree.Nodes) == 0 {
+		return SymmetryIdentity
+	}
+
+	width, height, err := gameTree.Nodes[0].BoardSize()
+	if err != nil {
+		return SymmetryIdentity
+	}
+
+	points := []Point{}
+	for _, node := range gameTree.MainLine() {
+		if color, point, pass, err := node.Move(width, height); err == nil && color != Empty && !pass {
+			points = append(points, point)
+		}
+	}
+
+	return normalizingSymmetry(points, width, height)
+}
+
+// Applies the symmetry to the point values of all the registered properties of the collection: moves, setup
+// stones, markup, arrows, lines, labels, territories, dimmed points and views. Board size of each GameTree is read
+// from its first Node. Returns an error, leaving the collection unchanged, if the symmetry is not valid for the board
+// size of a GameTree (see Symmetry.Valid) or a point is not on the board.
+func Transform(collection *Collection, symmetry Symmetry) error {
+	transformed := map[*Property][]string{}
+
+	for i, gameTree := range collection.GameTrees {
+		if len(gameTree.Nodes) == 0 {
+			continue
+		}
+
+		width, height, err := gameTree.Nodes[0].BoardSize()
+		if err != nil {
+			return err
+		}
+
+		if !symmetry.Valid(width, height) {
+			return errors.New(fmt.Sprintf("Symmetry %s is not valid for the %dx%d board of game %d", symmetry, width,
+				height, i+1))
+		}
+
+		if err := transformGameTree(transformed, gameTree, symmetry, width, height); err != nil {
+			return err
+		}
+	}
+
+	for property, values := range transformed {
+		property.Values = values
+	}
+
+	return nil
+}
+
+func transformGameTree(transformed map[*Property][]string, gameTree *GameTree, symmetry Symmetry, width, height int) error {
+	for _, node := range gameTree.Nodes {
+		for _, property := range node.Properties {
+			info, ok := LookupProperty(property.Ident)
+			if !ok || (info.Value != ValuePoint && info.Value != ValueMove && info.Value != ValueStone) {
+				continue
+			}
+
+			values := make([]string, len(property.Values))
+			for i, value := range property.Values {
+				v, err := transformValue(info, value, symmetry, width, height)
+				if err != nil {
+					return errors.New(fmt.Sprintf("%s: %s", property.Ident, err))
+				}
+
+				values[i] = v
+			}
+
+			transformed[property] = values
+		}
+	}
+
+	for _, childGameTree := range gameTree.GameTrees {
+		if err := transformGameTree(transformed, childGameTree, symmetry, width, height); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns a point value of the property moved by the symmetry. Points are never escaped, so the first ':' separates
+// the parts of composed values.
+func transformValue(info PropertyInfo, value string, symmetry Symmetry, width, height int) (string, error) {
+	if value == "" || (info.Value == ValueMove && value == "tt" && width <= 19 && height <= 19) {
+		return value, nil
+	}
+
+	separator := strings.Index(value, ":")
+
+	switch {
+	case separator < 0:
+		return transformPoint(value, symmetry, width, height)
+	case info.Composed == ValueSimpleText:
+		// Label, only the point is moved
+		point, err := transformPoint(value[:separator], symmetry, width, height)
+		return point + value[separator:], err
+	}
+
+	from, err := transformPoint(value[:separator], symmetry, width, height)
+	if err != nil {
+		return "", err
+	}
+
+	to, err := transformPoint(value[separator+1:], symmetry, width, height)
+	if err != nil {
+		return "", err
+	}
+
+	if info.Composed == ValuePoint {
+		// Arrow or line, the direction is kept
+		return from + ":" + to, nil
+	}
+
+	// Compressed point list, the corners of the rectangle are moved to the upper left and the lower right
+	upperLeft, _ := ParsePoint(from)
+	lowerRight, _ := ParsePoint(to)
+
+	if upperLeft.X > lowerRight.X {
+		upperLeft.X, lowerRight.X = lowerRight.X, upperLeft.X
+	}
+
+	if upperLeft.Y > lowerRight.Y {
+		upperLeft.Y, lowerRight.Y = lowerRight.Y, upperLeft.Y
+	}
+
+	return upperLeft.String() + ":" + lowerRight.String(), nil
+}
+
+func transformPoint(value string, symmetry Symmetry, width, height int) (string, error) {
+	point, err := ParsePoint(value)
+	if err != nil {
+		return "", err
+	}
+
+	if !point.OnBoard(width, height) {
+		return "", errors.New(fmt.Sprintf("Point %s is not on the board", point))
+	}
+
+	return symmetry.Point(point, width, height).String(), nil
+}
@@ -0,0 +1,132 @@
+package sgf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSymmetryPoint(t *testing.T) {
+	// Images of the upper right corner point of a 19x19 and a 13x9 board
+	var tests = []struct {
+		symmetry Symmetry
+		square   Point
+		wide     Point
+	}{
+		{SymmetryIdentity, Point{15, 2}, Point{10, 2}},
+		{SymmetryRotate90, Point{16, 15}, Point{6, 10}},
+		{SymmetryRotate180, Point{3, 16}, Point{2, 6}},
+		{SymmetryRotate270, Point{2, 3}, Point{2, 2}},
+		{SymmetryMirror, Point{3, 2}, Point{2, 2}},
+		{SymmetryFlip, Point{15, 16}, Point{10, 6}},
+		{SymmetryTranspose, Point{2, 15}, Point{2, 10}},
+		{SymmetryAntiTranspose, Point{16, 3}, Point{6, 2}},
+	}
+
+	for _, test := range tests {
+		if p := test.symmetry.Point(Point{15, 2}, 19, 19); p != test.square {
+			t.Errorf("%s.Point() mismatch. Wanted: %v, got: %v", test.symmetry, test.square, p)
+		}
+
+		if p := test.symmetry.Point(Point{10, 2}, 13, 9); p != test.wide {
+			t.Errorf("%s.Point() on 13x9 mismatch. Wanted: %v, got: %v", test.symmetry, test.wide, p)
+		}
+	}
+
+	wanted := []Symmetry{SymmetryIdentity, SymmetryRotate180, SymmetryMirror, SymmetryFlip}
+	if symmetries := Symmetries(13, 9); !reflect.DeepEqual(symmetries, wanted) {
+		t.Errorf("Symmetries(13, 9) mismatch. Got: %v", symmetries)
+	}
+
+	if len(Symmetries(9, 9)) != 8 {
+		t.Errorf("Symmetries(9, 9) mismatch.")
+	}
+}
+
+func TestTransform(t *testing.T) {
+	var tests = []struct {
+		sgf         string
+		symmetry    Symmetry
+		transformed string
+	}{
+		{"(;SZ[19]AB[aa][bb:cd]AE[ss];B[pd]CR[pd]LB[pd:A\\:b]AR[aa:bc];W[tt]VW[]DD[aa:ss];B[])", SymmetryMirror,
+			"(;SZ[19]AB[sa][qb:rd]AE[as];B[dd]CR[dd]LB[dd:A\\:b]AR[sa:rc];W[tt]VW[]DD[aa:ss];B[])"},
+		{"(;SZ[19];B[pd](;W[dp]LN[aa:ab])(;W[qq]TR[ac]))", SymmetryRotate90,
+			"(;SZ[19];B[pp](;W[dd]LN[sa:ra])(;W[cq]TR[qa]))"},
+		{"(;SZ[13:9]TB[aa:bc];B[ma]XX[aa])", SymmetryRotate180, "(;SZ[13:9]TB[lg:mi];B[ai]XX[aa])"},
+		{"(;SZ[21];B[tt])", SymmetryTranspose, "(;SZ[21];B[tt])"},
+		{"(;SZ[21];B[ut])", SymmetryTranspose, "(;SZ[21];B[tu])"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.sgf)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test.sgf, err)
+		}
+
+		if err := Transform(collection, test.symmetry); err != nil {
+			t.Errorf("Transform(%s, %s) returned error: %s", test.sgf, test.symmetry, err)
+			continue
+		}
+
+		if s := collection.Sgf(SgfFormat{}); s != test.transformed {
+			t.Errorf("Transform(%s, %s) mismatch.\nWanted: %s\ngot:    %s", test.sgf, test.symmetry, test.transformed, s)
+		}
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	var tests = []struct {
+		sgf      string
+		symmetry Symmetry
+	}{
+		{"(;SZ[13:9];B[aa])", SymmetryRotate90},
+		{"(;SZ[19];B[aa])(;SZ[13:9];B[aa])", SymmetryTranspose},
+		{"(;SZ[9];B[aa];W[kk])", SymmetryMirror},
+		{"(;SZ[9];B[aa];LB[a:A])", SymmetryMirror},
+	}
+
+	for _, test := range tests {
+		collection, _ := ParseSgf(test.sgf)
+
+		if err := Transform(collection, test.symmetry); err == nil {
+			t.Errorf("Transform(%s, %s) did not return error.", test.sgf, test.symmetry)
+		}
+
+		// Collection is left unchanged
+		if s := collection.Sgf(SgfFormat{}); s != test.sgf {
+			t.Errorf("Transform(%s, %s) changed the collection: %s", test.sgf, test.symmetry, s)
+		}
+	}
+}
+
+func TestCanonicalSymmetry(t *testing.T) {
+	var tests = []struct {
+		sgf      string
+		symmetry Symmetry
+	}{
+		{"(;B[pd];W[dp])", SymmetryIdentity},
+		{"(;B[dd];W[pp])", SymmetryRotate90},
+		{"(;B[dp];W[pd])", SymmetryRotate180},
+		{"(;B[pd];W[dp];B[pp])", SymmetryAntiTranspose},
+		{"(;B[jj];W[qc])", SymmetryIdentity},
+		{"(;B[jj];W[cq])", SymmetryRotate180},
+		{"(;SZ[13:9];B[cg])", SymmetryRotate180},
+		{"(;GM[1])", SymmetryIdentity},
+	}
+
+	for _, test := range tests {
+		collection, _ := ParseSgf(test.sgf)
+		gameTree := collection.GameTrees[0]
+
+		symmetry := CanonicalSymmetry(gameTree)
+		if symmetry != test.symmetry {
+			t.Errorf("CanonicalSymmetry(%s) mismatch. Wanted: %s, got: %s", test.sgf, test.symmetry, symmetry)
+		}
+
+		// Canonical game is its own canonical form
+		Transform(collection, symmetry)
+		if symmetry := CanonicalSymmetry(gameTree); symmetry != SymmetryIdentity {
+			t.Errorf("CanonicalSymmetry(%s) of the transformed game returned %s", test.sgf, symmetry)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package sgf
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Decodes a Text value as it is written in a SGF file (without the enclosing brackets). As described in FF[4]:
+//   - "\" escapes the next character, so "\]" becomes "]" and "\\" becomes "\".
+//   - Escaped linebreaks are soft linebreaks and they are removed.
+//   - Linebreaks ("\n", "\r", "\r\n" and "\n\r") are converted to "\n".
+//   - Other whitespace characters are converted to spaces.
+//
+// Note that the parser already removes escaping from Property.Values, use Property.DecodedValues for them.
+func DecodeText(value string) string {
+	return normalizeText(unescapeValue(value))
+}
+
+// Decodes a SimpleText value as it is written in a SGF file (without the enclosing brackets). Escaping and soft
+// linebreaks are handled as in DecodeText, but all the whitespace characters including linebreaks are converted to
+// spaces.
+func DecodeSimpleText(value string) string {
+	return normalizeSimpleText(unescapeValue(value))
+}
+
+// Encodes the text to a Text value written in a SGF file. Linebreaks are written as "\n" and "\" and "]" are escaped.
+func EncodeText(text string) string {
+	return escapeValue(normalizeLinebreaks(text))
+}
+
+// Encodes the text to a SimpleText value written in a SGF file. Linebreaks are converted to spaces as they would be
+// when decoding and "\" and "]" are escaped.
+func EncodeSimpleText(text string) string {
+	return escapeValue(normalizeSimpleText(text))
+}
+
+// Removes escaping and soft linebreaks from the value.
+func unescapeValue(value string) string {
+	return unescape(value, false)
+}
+
+// Removes escaping and soft linebreaks from the value of a composed property. Escaped "\" and ":" are kept escaped.
+func unescapeComposedValue(value string) string {
+	return unescape(value, true)
+}
+
+func unescape(value string, composed bool) string {
+	var buffer bytes.Buffer
+	escaped := false
+	var pair rune // second character of an escaped two character linebreak
+
+	for i, c := range value {
+		if pair != 0 {
+			skip := c == pair
+			pair = 0
+
+			if skip {
+				continue
+			}
+		}
+
+		if escaped {
+			escaped = false
+
+			// Soft linebreak
+			switch c {
+			case '\n':
+				pair = '\r'
+				continue
+			case '\r':
+				pair = '\n'
+				continue
+			}
+
+			if composed && (c == '\\' || c == ':') {
+				buffer.WriteRune('\\')
+			}
+
+			writeRuneAt(&buffer, value, i)
+			continue
+		}
+
+		if c == '\\' {
+			escaped = true
+			continue
+		}
+
+		writeRuneAt(&buffer, value, i)
+	}
+
+	return buffer.String()
+}
+
+// Writes the character starting at the byte offset of the value as it is, also when it is not valid UTF-8.
+func writeRuneAt(buffer *bytes.Buffer, value string, offset int) {
+	_, size := utf8.DecodeRuneInString(value[offset:])
+	buffer.WriteString(value[offset : offset+size])
+}
+
+// Escapes "\" and "]" in the value.
+func escapeValue(value string) string {
+	escaped := strings.Replace(value, "\\", "\\\\", -1)
+	return strings.Replace(escaped, "]", "\\]", -1)
+}
+
+var linebreakReplacer = strings.NewReplacer("\r\n", "\n", "\n\r", "\n", "\r", "\n")
+
+func normalizeLinebreaks(value string) string {
+	return linebreakReplacer.Replace(value)
+}
+
+// Converts linebreaks to "\n" and other whitespace to spaces.
+func normalizeText(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && isWhitespace(r) {
+			return ' '
+		}
+
+		return r
+	}, normalizeLinebreaks(value))
+}
+
+// Converts all whitespace to spaces.
+func normalizeSimpleText(value string) string {
+	return strings.Map(func(r rune) rune {
+		if isWhitespace(r) {
+			return ' '
+		}
+
+		return r
+	}, normalizeLinebreaks(value))
+}
+
+func isWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\v', '\f', '\r', '\n':
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	var tests = []struct {
+		data       string
+		text       string
+		simpleText string
+	}{
+		{"", "", ""},
+		{"foo", "foo", "foo"},
+		{"foo\\]\\\\", "foo]\\", "foo]\\"},
+		{"a\tb\vc", "a b c", "a b c"},
+		{"line\nbreak", "line\nbreak", "line break"},
+		{"line\r\nbreak", "line\nbreak", "line break"},
+		{"line\n\rbreak", "line\nbreak", "line break"},
+		{"line\rbreak", "line\nbreak", "line break"},
+		{"soft\\\nbreak", "softbreak", "softbreak"},
+		{"soft\\\r\nbreak", "softbreak", "softbreak"},
+		{"soft\\\n\rbreak", "softbreak", "softbreak"},
+		{"soft\\\n\nbreak", "soft\nbreak", "soft break"},
+		{"\\:", ":", ":"},
+	}
+
+	for _, test := range tests {
+		if text := DecodeText(test.data); text != test.text {
+			t.Errorf("DecodeText(%q) mismatch. wanted: %q, got: %q.", test.data, test.text, text)
+		}
+
+		if text := DecodeSimpleText(test.data); text != test.simpleText {
+			t.Errorf("DecodeSimpleText(%q) mismatch. wanted: %q, got: %q.", test.data, test.simpleText, text)
+		}
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	var tests = []struct {
+		text       string
+		value      string
+		simpleText string
+	}{
+		{"foo", "foo", "foo"},
+		{"[rank]: \\o", "[rank\\]: \\\\o", "[rank\\]: \\\\o"},
+		{"line\r\nbreak", "line\nbreak", "line break"},
+	}
+
+	for _, test := range tests {
+		if value := EncodeText(test.text); value != test.value {
+			t.Errorf("EncodeText(%q) mismatch. wanted: %q, got: %q.", test.text, test.value, value)
+		}
+
+		if value := EncodeSimpleText(test.text); value != test.simpleText {
+			t.Errorf("EncodeSimpleText(%q) mismatch. wanted: %q, got: %q.", test.text, test.simpleText, value)
+		}
+
+		if text := DecodeText(EncodeText(test.text)); text != normalizeText(test.text) {
+			t.Errorf("DecodeText(EncodeText(%q)) mismatch. Got: %q.", test.text, text)
+		}
+	}
+}
@@ -16,7 +16,9 @@ type Node struct {
 	Properties []*Property // zero or more
 }
 
-// Property contains ident string and one or more values.
+// Property contains ident string and one or more values. Values are unescaped, except for the values of composed
+// properties (see PropertyInfo.Composed) which keep "\:" and "\\" escaped so that the separating ':' can be told
+// apart. Use Compose.Split to get the unescaped parts of a composed value, on DecodedValues if the parts are text.
 type Property struct {
 	Ident  string
 	Values []string // at least one
@@ -0,0 +1,523 @@
+/*
+Package ugf converts Pandanet IGS UGF and UGI game records to sgf Collections and back.
+
+UGF file is divided to sections. Header section contains the game information, Data section a line per move and
+Figure section the figures and the comments of the moves:
+
+	[Header]
+	Title=Jubango Game 1,
+	Place=Beijing
+	Date=2014/01/26
+	Size=19
+	Hdcp=0,7.5
+	PlayerB=Lee Sedol,9p,
+	PlayerW=Gu Li,9p,
+	Winner=W,R
+	[Data]
+	QP,B1,1,0
+	DD,W2,2,0
+	[Figure]
+	.Fig,1,1
+	.Comment,1
+	Standard opening.
+	.EndComment
+	.Mark,2,TR,DD
+
+Data line contains the coordinates, color and move number, node number and the time used. Columns are counted from
+the left and rows from the bottom, "YA" is a pass.
+
+Figures start a new figure (FG) at the node of the given move, comments are added as C and marks as markup. Mark
+type is one of CR, SQ, TR and MA, any other type is used as the text of a label (LB). Move number 0 refers to the root
+node. Comment lines that would read as .EndComment are escaped with an extra leading dot. UGI files use the same
+format.
+*/
+package ugf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/toikarin/sgf"
+)
+
+const pass = "YA"
+
+var readFileFunc func(string) ([]byte, error) = ioutil.ReadFile
+
+// Parse given filename as a UGF or UGI file.
+func ParseFile(filename string) (*sgf.Collection, error) {
+	bytes, err := readFileFunc(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(string(bytes))
+}
+
+// Parse given data as a UGF or UGI file. The returned collection contains a single GameTree.
+func Parse(data string) (*sgf.Collection, error) {
+	lines := strings.Split(strings.Replace(data, "\r", "", -1), "\n")
+	header := map[string][]string{}
+	section := ""
+
+	collection, gameTree, root := sgf.NewCollection()
+	root.NewProperty("FF", "4")
+	root.NewProperty("GM", "1")
+
+	// Nodes by move number, root is move 0
+	nodes := []*sgf.Node{root}
+	size := 0
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+
+			if section != "Header" && size == 0 {
+				// Game information is needed before the moves and the figures
+				var err error
+				if size, err = addGameInfo(root, header); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		switch section {
+		case "Header":
+			kv := strings.SplitN(line, "=", 2)
+			if len(kv) == 2 {
+				header[kv[0]] = strings.Split(kv[1], ",")
+			}
+		case "Data":
+			fields := strings.Split(line, ",")
+			if len(fields) < 2 || len(fields[0]) != 2 || len(fields[1]) < 1 {
+				return nil, createError("Invalid move", i)
+			}
+
+			color := fields[1][:1]
+			if color != "B" && color != "W" {
+				return nil, createError(fmt.Sprintf("Invalid color %s", color), i)
+			}
+
+			value := ""
+			if fields[0] != pass {
+				point := sgf.Point{X: int(fields[0][0] - 'A'), Y: size - 1 - int(fields[0][1]-'A')}
+				if !point.OnBoard(size, size) {
+					return nil, createError(fmt.Sprintf("Invalid coordinates %s", fields[0]), i)
+				}
+
+				value = point.String()
+			}
+
+			node := gameTree.NewNode()
+			node.NewProperty(color, value)
+			nodes = append(nodes, node)
+		case "Figure":
+			end, err := parseFigureLine(lines, i, nodes, size)
+			if err != nil {
+				return nil, err
+			}
+
+			i = end
+		}
+	}
+
+	if size == 0 {
+		return nil, errors.New("Missing sections")
+	}
+
+	return collection, nil
+}
+
+// Adds the game information from the header to the root node. Returns the board size.
+func addGameInfo(root *sgf.Node, header map[string][]string) (int, error) {
+	get := func(key string, i int) string {
+		if values := header[key]; len(values) > i {
+			return strings.TrimSpace(values[i])
+		}
+
+		return ""
+	}
+
+	size := 19
+	if s := get("Size", 0); s != "" {
+		var err error
+		if size, err = strconv.Atoi(s); err != nil || size < 2 || size > 25 {
+			return 0, errors.New(fmt.Sprintf("Invalid board size %s", s))
+		}
+	}
+
+	root.NewProperty("SZ", strconv.Itoa(size))
+
+	for _, info := range []struct {
+		key   string
+		i     int
+		ident string
+	}{
+		{"Title", 0, "EV"},
+		{"Place", 0, "PC"},
+		{"PlayerB", 0, "PB"},
+		{"PlayerB", 1, "BR"},
+		{"PlayerW", 0, "PW"},
+		{"PlayerW", 1, "WR"},
+		{"Rule", 0, "RU"},
+	} {
+		if value := get(info.key, info.i); value != "" {
+			root.NewProperty(info.ident, value)
+		}
+	}
+
+	if date := get("Date", 0); date != "" {
+		root.NewProperty("DT", strings.Replace(date, "/", "-", -1))
+	}
+
+	if komi, err := strconv.ParseFloat(get("Hdcp", 1), 64); err == nil {
+		root.NewProperty("KM", strconv.FormatFloat(komi, 'f', -1, 64))
+	}
+
+	if result := parseResult(get("Winner", 0), get("Winner", 1)); result != "" {
+		root.NewProperty("RE", result)
+	}
+
+	handicap, _ := strconv.Atoi(get("Hdcp", 0))
+	if handicap >= 2 {
+		points := sgf.HandicapPoints(size, handicap)
+		if points == nil {
+			return 0, errors.New(fmt.Sprintf("Invalid handicap %d", handicap))
+		}
+
+		root.NewProperty("HA", strconv.Itoa(handicap))
+		root.NewProperty("AB", sgf.CompressPoints(points)...)
+	}
+
+	return size, nil
+}
+
+func parseResult(winner, score string) string {
+	switch winner {
+	case "D":
+		return "0"
+	case "B", "W":
+	default:
+		return ""
+	}
+
+	switch score {
+	case "R", "-1":
+		return winner + "+R"
+	case "T":
+		return winner + "+T"
+	case "":
+		return winner + "+"
+	}
+
+	return winner + "+" + score
+}
+
+// Parses the figure section line at the given index. Returns the index of the last line used.
+func parseFigureLine(lines []string, i int, nodes []*sgf.Node, size int) (int, error) {
+	fields := strings.Split(strings.TrimSpace(lines[i]), ",")
+
+	node := func(field string) (*sgf.Node, error) {
+		move, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || move < 0 || move >= len(nodes) {
+			return nil, createError(fmt.Sprintf("Invalid move number %s", field), i)
+		}
+
+		return nodes[move], nil
+	}
+
+	switch fields[0] {
+	case ".Fig":
+		if len(fields) < 3 {
+			return 0, createError("Invalid figure", i)
+		}
+
+		n, err := node(fields[2])
+		if err != nil {
+			return 0, err
+		}
+
+		n.NewProperty("FG", string(sgf.NewCompose("0", "Figure "+strings.TrimSpace(fields[1]))))
+	case ".Comment":
+		if len(fields) < 2 {
+			return 0, createError("Invalid comment", i)
+		}
+
+		n, err := node(fields[1])
+		if err != nil {
+			return 0, err
+		}
+
+		comment := []string{}
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) != ".EndComment"; i++ {
+			line := lines[i]
+			if isEndComment(line) {
+				line = strings.Replace(line, "..", ".", 1)
+			}
+
+			comment = append(comment, line)
+		}
+
+		if i == len(lines) {
+			return 0, errors.New("Comment left open")
+		}
+
+		n.NewProperty("C", strings.Join(comment, "\n"))
+	case ".Mark":
+		if len(fields) < 4 || len(fields[3]) != 2 {
+			return 0, createError("Invalid mark", i)
+		}
+
+		n, err := node(fields[1])
+		if err != nil {
+			return 0, err
+		}
+
+		point := sgf.Point{X: int(fields[3][0] - 'A'), Y: size - 1 - int(fields[3][1]-'A')}
+		if !point.OnBoard(size, size) {
+			return 0, createError(fmt.Sprintf("Invalid coordinates %s", fields[3]), i)
+		}
+
+		var shape sgf.Shape
+		switch fields[2] {
+		case "CR":
+			shape = sgf.ShapeCircle
+		case "SQ":
+			shape = sgf.ShapeSquare
+		case "TR":
+			shape = sgf.ShapeTriangle
+		case "MA":
+			shape = sgf.ShapeCross
+		default:
+			return i, n.SetLabel(point, fields[2])
+		}
+
+		return i, n.SetShape(point, shape)
+	}
+
+	return i, nil
+}
+
+// Converts the main line of the first GameTree in the collection to UGF format. Comments, figures and markup of the
+// main line are written to the figure section. Only moves and handicap stones are supported, other setup properties
+// return an error. Boards larger than 24x24 are not supported.
+func Ugf(collection *sgf.Collection) (string, error) {
+	if len(collection.GameTrees) == 0 {
+		return "", errors.New("Collection does not contain any GameTrees")
+	}
+
+	nodes := collection.GameTrees[0].MainLine()
+	if len(nodes) == 0 {
+		return "", errors.New("GameTree does not contain any Nodes")
+	}
+
+	root := nodes[0]
+
+	// On larger boards the last point would be written as the pass "YA"
+	size := 19
+	if s := value(root, "SZ"); s != "" {
+		var err error
+		if size, err = strconv.Atoi(s); err != nil || size < 2 || size > 24 {
+			return "", errors.New(fmt.Sprintf("Unsupported board size %s", s))
+		}
+	}
+
+	var buffer bytes.Buffer
+	writeHeader(&buffer, root, size)
+
+	var figures bytes.Buffer
+	figureCount := 0
+
+	buffer.WriteString("[Data]\n")
+
+	handicap, _ := strconv.Atoi(value(root, "HA"))
+
+	move := 0
+	for i, node := range nodes {
+		for _, property := range node.Properties {
+			switch property.Ident {
+			case "B", "W":
+				move++
+
+				coordinates := pass
+				if v := property.Values[0]; v != "" && !(v == "tt" && size <= 19) {
+					point, err := sgf.ParsePoint(v)
+					if err != nil {
+						return "", err
+					}
+
+					if !point.OnBoard(size, size) {
+						return "", errors.New(fmt.Sprintf("Invalid move %s", v))
+					}
+
+					coordinates = ugfCoordinates(point, size)
+				}
+
+				buffer.WriteString(fmt.Sprintf("%s,%s%d,%d,0\n", coordinates, property.Ident, move, move))
+			case "AB":
+				if i == 0 && isHandicapSetup(property, size, handicap) {
+					continue
+				}
+
+				return "", errors.New("Setup stones are not supported")
+			case "AW", "AE":
+				return "", errors.New("Setup stones are not supported")
+			}
+		}
+
+		if i > 0 && move == 0 {
+			// Figure section refers to moves, setup nodes can not be referred
+			continue
+		}
+
+		if err := writeFigures(&figures, node, move, size, &figureCount); err != nil {
+			return "", err
+		}
+	}
+
+	buffer.WriteString("[Figure]\n")
+	buffer.Write(figures.Bytes())
+
+	return buffer.String(), nil
+}
+
+func writeHeader(buffer *bytes.Buffer, root *sgf.Node, size int) {
+	buffer.WriteString("[Header]\n")
+
+	writeLine := func(key string, values ...string) {
+		if values[0] != "" {
+			buffer.WriteString(key + "=" + strings.Join(values, ",") + "\n")
+		}
+	}
+
+	writeLine("Title", value(root, "EV"))
+	writeLine("Place", value(root, "PC"))
+	writeLine("Date", strings.Replace(value(root, "DT"), "-", "/", -1))
+	writeLine("Rule", value(root, "RU"))
+	writeLine("Size", strconv.Itoa(size))
+
+	handicap := value(root, "HA")
+	if handicap == "" {
+		handicap = "0"
+	}
+	writeLine("Hdcp", handicap, value(root, "KM"))
+	writeLine("PlayerB", value(root, "PB"), value(root, "BR"))
+	writeLine("PlayerW", value(root, "PW"), value(root, "WR"))
+
+	if result := value(root, "RE"); result == "0" || result == "Draw" {
+		writeLine("Winner", "D")
+	} else if len(result) >= 2 && result[1] == '+' {
+		score := result[2:]
+		switch score {
+		case "Resign":
+			score = "R"
+		case "Time":
+			score = "T"
+		}
+
+		writeLine("Winner", result[:1], score)
+	}
+}
+
+func writeFigures(buffer *bytes.Buffer, node *sgf.Node, move, size int, figureCount *int) error {
+	if node.Property("FG") != nil {
+		*figureCount++
+		buffer.WriteString(fmt.Sprintf(".Fig,%d,%d\n", *figureCount, move))
+	}
+
+	if comment := node.Property("C"); comment != nil {
+		lines := strings.Split(comment.DecodedValues()[0], "\n")
+		for i, line := range lines {
+			if isEndComment(line) {
+				lines[i] = strings.Replace(line, ".", "..", 1)
+			}
+		}
+
+		buffer.WriteString(fmt.Sprintf(".Comment,%d\n%s\n.EndComment\n", move, strings.Join(lines, "\n")))
+	}
+
+	shapes, err := node.Shapes()
+	if err != nil {
+		return err
+	}
+
+	// Write the shapes in a stable order
+	for y := size - 1; y >= 0; y-- {
+		for x := 0; x < size; x++ {
+			if shape := shapes[sgf.Point{X: x, Y: y}]; shape != sgf.ShapeNone && shape != sgf.ShapeSelected {
+				buffer.WriteString(fmt.Sprintf(".Mark,%d,%s,%s\n", move, shape.Ident(), ugfCoordinates(sgf.Point{X: x, Y: y}, size)))
+			}
+		}
+	}
+
+	labels, err := node.Labels()
+	if err != nil {
+		return err
+	}
+
+	for _, label := range labels {
+		buffer.WriteString(fmt.Sprintf(".Mark,%d,%s,%s\n", move, label.Text, ugfCoordinates(label.Point, size)))
+	}
+
+	return nil
+}
+
+// Check if the AB property contains exactly the handicap stones.
+func isHandicapSetup(property *sgf.Property, size, handicap int) bool {
+	points, err := sgf.ParsePointList(property.Values...)
+	if err != nil {
+		return false
+	}
+
+	handicapPoints := sgf.HandicapPoints(size, handicap)
+	if len(points) != len(handicapPoints) {
+		return false
+	}
+
+	expected := map[sgf.Point]bool{}
+	for _, point := range handicapPoints {
+		expected[point] = true
+	}
+
+	for _, point := range points {
+		if !expected[point] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func ugfCoordinates(point sgf.Point, size int) string {
+	return string([]byte{byte('A' + point.X), byte('A' + size - 1 - point.Y)})
+}
+
+// Returns the first value of the property or an empty string.
+func value(node *sgf.Node, ident string) string {
+	if property := node.Property(ident); property != nil && len(property.Values) > 0 {
+		return property.Values[0]
+	}
+
+	return ""
+}
+
+func createError(msg string, line int) error {
+	return errors.New(fmt.Sprintf("%s [line %d]", msg, line+1))
+}
+
+// Checks if the comment line is .EndComment preceded by any number of extra dots. Those lines are written with one
+// more dot so that the comment does not end early.
+func isEndComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, ".") && strings.TrimLeft(line, ".") == "EndComment"
+}
@@ -0,0 +1,186 @@
+package ugf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+func TestParseFile(t *testing.T) {
+	var tests = []struct {
+		filename string
+		wanted   string
+	}{
+		{"testdata/jubango.ugf",
+			"(;FF[4]GM[1]SZ[19]EV[Jubango Game 1]PC[Beijing]PB[Lee Sedol]BR[9p]PW[Gu Li]WR[9p]RU[JPN]DT[2014-01-26]KM[7.5]" +
+				"RE[W+R];B[pd]FG[0:Figure 1]C[Standard opening.\nBlack takes the corner.];W[dp]TR[dp]LB[qp:A];B[qp];W[]" +
+				";B[cd]C[White resigned later.])"},
+		{"testdata/handicap.ugi",
+			"(;FF[4]GM[1]SZ[13]PB[Amateur]BR[3k]PW[Teacher]WR[5d]KM[0.5]RE[B+3.5]HA[2]AB[jd][dj]SQ[dd];W[jc];B[kk])"},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseFile(test.filename)
+		if err != nil {
+			t.Errorf("ParseFile(%s) returned error: %s", test.filename, err)
+			continue
+		}
+
+		if sgf := collection.Sgf(sgf.NoNewLinesSgfFormat); sgf != test.wanted {
+			t.Errorf("ParseFile(%s) mismatch. Got: %s.", test.filename, sgf)
+		}
+	}
+}
+
+func TestReadFileError(t *testing.T) {
+	oldReadFileFunc := readFileFunc
+	defer func() {
+		readFileFunc = oldReadFileFunc
+	}()
+
+	readFileFunc = func(filename string) ([]byte, error) {
+		return nil, errors.New("")
+	}
+
+	if _, err := ParseFile("foo"); err == nil {
+		t.Errorf("ParseFile did not return error.")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var errTests = []string{
+		"",
+		"[Header]\nSize=x\n[Data]\n",
+		"[Header]\nSize=9\n[Data]\nTA,B1,1,0\n",
+		"[Header]\nSize=9\n[Data]\nAA,X1,1,0\n",
+		"[Header]\nSize=9\n[Data]\nA,B1,1,0\n",
+		"[Header]\nSize=9\nHdcp=12,0\n[Data]\n",
+		"[Data]\nAA,B1,1,0\n[Figure]\n.Comment,2\nfoo\n.EndComment\n",
+		"[Data]\nAA,B1,1,0\n[Figure]\n.Comment,1\nfoo\n",
+		"[Data]\nAA,B1,1,0\n[Figure]\n.Mark,1,TR,ZZ\n",
+		"[Data]\nAA,B1,1,0\n[Figure]\n.Fig,1\n",
+	}
+
+	for _, test := range errTests {
+		if _, err := Parse(test); err == nil {
+			t.Errorf("Parse(%q) did not return error.", test)
+		}
+	}
+}
+
+func TestUgf(t *testing.T) {
+	collection, err := ParseFile("testdata/jubango.ugf")
+	if err != nil {
+		t.Fatalf("ParseFile returned error: %s", err)
+	}
+
+	wanted := "[Header]\n" +
+		"Title=Jubango Game 1\n" +
+		"Place=Beijing\n" +
+		"Date=2014/01/26\n" +
+		"Rule=JPN\n" +
+		"Size=19\n" +
+		"Hdcp=0,7.5\n" +
+		"PlayerB=Lee Sedol,9p\n" +
+		"PlayerW=Gu Li,9p\n" +
+		"Winner=W,R\n" +
+		"[Data]\n" +
+		"PP,B1,1,0\n" +
+		"DD,W2,2,0\n" +
+		"QD,B3,3,0\n" +
+		"YA,W4,4,0\n" +
+		"CP,B5,5,0\n" +
+		"[Figure]\n" +
+		".Fig,1,1\n" +
+		".Comment,1\n" +
+		"Standard opening.\n" +
+		"Black takes the corner.\n" +
+		".EndComment\n" +
+		".Mark,2,TR,DD\n" +
+		".Mark,2,A,QD\n" +
+		".Comment,5\n" +
+		"White resigned later.\n" +
+		".EndComment\n"
+
+	if ugf, err := Ugf(collection); err != nil {
+		t.Errorf("Ugf returned error: %s", err)
+	} else if ugf != wanted {
+		t.Errorf("Ugf mismatch. Got: %s.", ugf)
+	}
+}
+
+func TestUgfRoundTrip(t *testing.T) {
+	for _, filename := range []string{"testdata/jubango.ugf", "testdata/handicap.ugi"} {
+		collection, err := ParseFile(filename)
+		if err != nil {
+			t.Fatalf("ParseFile(%s) returned error: %s", filename, err)
+		}
+
+		ugf, err := Ugf(collection)
+		if err != nil {
+			t.Errorf("Ugf(%s) returned error: %s", filename, err)
+			continue
+		}
+
+		parsed, err := Parse(ugf)
+		if err != nil {
+			t.Errorf("Parse(Ugf(%s)) returned error: %s", filename, err)
+			continue
+		}
+
+		if parsed.Sgf(sgf.NoNewLinesSgfFormat) != collection.Sgf(sgf.NoNewLinesSgfFormat) {
+			t.Errorf("Parse(Ugf(%s)) mismatch. Got: %s.", filename, parsed.Sgf(sgf.NoNewLinesSgfFormat))
+		}
+	}
+}
+
+func TestUgfCommentRoundTrip(t *testing.T) {
+	comment := "a\n.EndComment\n  ..EndComment\n.EndComments\nb"
+
+	collection, err := sgf.ParseSgf("(;C[" + comment + "];B[aa])")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	ugf, err := Ugf(collection)
+	if err != nil {
+		t.Fatalf("Ugf returned error: %s", err)
+	}
+
+	parsed, err := Parse(ugf)
+	if err != nil {
+		t.Fatalf("Parse(Ugf) returned error: %s", err)
+	}
+
+	if c := parsed.GameTrees[0].Nodes[0].Property("C"); c == nil || c.Values[0] != comment {
+		t.Errorf("Parse(Ugf) comment mismatch. Wanted: %q, got: %v. UGF:\n%s", comment, c, ugf)
+	}
+}
+
+func TestUgfErrors(t *testing.T) {
+	var errTests = []string{
+		"(;SZ[foo];B[aa])",
+		"(;AW[aa];B[bb])",
+		"(;HA[2]AB[aa];W[bb])",
+		"(;B[zz])",
+		"(;SZ[25];B[yy])",
+	}
+
+	for _, test := range errTests {
+		collection, err := sgf.ParseSgf(test)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error.", test)
+		}
+
+		if _, err := Ugf(collection); err == nil {
+			t.Errorf("Ugf(%s) did not return error.", test)
+		}
+	}
+
+	for _, collection := range []*sgf.Collection{{}, {GameTrees: []*sgf.GameTree{{}}}} {
+		if _, err := Ugf(collection); err == nil {
+			t.Errorf("Ugf(%v) did not return error.", collection)
+		}
+	}
+}
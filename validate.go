@@ -0,0 +1,250 @@
+package sgf
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Issue is a problem found by Collection.Validate.
+type Issue struct {
+	Node     *Node     // Node of the problem, nil if the problem concerns the whole collection or a GameTree
+	Property *Property // Property of the problem, nil if the problem concerns the whole Node
+	Message  string
+}
+
+func (issue Issue) String() string {
+	if issue.Property != nil {
+		return fmt.Sprintf("%s: %s", issue.Property.Ident, issue.Message)
+	}
+
+	return issue.Message
+}
+
+// Checks the collection against the SGF specification and the property registry and returns the problems found, in
+// the order of the Nodes. Collection is checked for:
+//   - Structure, see Collection.Valid.
+//   - Values not matching the value type of the property, e.g. a point outside of the board (SZ of the root Node).
+//   - Several values given to a property that does not take a list, or an empty list.
+//   - The same property given more than once in a Node.
+//   - Root properties outside of the root Node and move properties in Nodes without a move.
+//   - Move and setup properties in the same Node.
+//   - Game info properties given again in a Node below the Node where game info was first given.
+//   - Markup, see Node.ValidateMarkup.
+//
+// Private and unknown properties are not checked.
+func (collection *Collection) Validate() []Issue {
+	v := &validator{}
+
+	if len(collection.GameTrees) == 0 {
+		v.add(nil, nil, "Collection does not have any GameTrees")
+	}
+
+	for _, gameTree := range collection.GameTrees {
+		v.width, v.height = 52, 52
+		if len(gameTree.Nodes) > 0 {
+			width, height, err := gameTree.Nodes[0].BoardSize()
+			if err == nil {
+				v.width, v.height = width, height
+			} else {
+				v.add(gameTree.Nodes[0], gameTree.Nodes[0].Property("SZ"), "%s", err)
+			}
+		}
+
+		v.validateGameTree(gameTree, true, nil)
+	}
+
+	return v.issues
+}
+
+type validator struct {
+	issues []Issue
+	width  int
+	height int
+}
+
+func (v *validator) add(node *Node, property *Property, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{node, property, fmt.Sprintf(format, args...)})
+}
+
+// Validates the GameTree. Game info Node is the Node above the GameTree containing game info properties.
+func (v *validator) validateGameTree(gameTree *GameTree, root bool, gameInfo *Node) {
+	if len(gameTree.Nodes) == 0 {
+		v.add(nil, nil, "GameTree does not have any Nodes")
+	}
+
+	for i, node := range gameTree.Nodes {
+		gameInfo = v.validateNode(node, root && i == 0, gameInfo)
+	}
+
+	for _, child := range gameTree.GameTrees {
+		v.validateGameTree(child, false, gameInfo)
+	}
+}
+
+// Properties checked by Node.ValidateMarkup.
+var markupIdents = map[string]bool{"CR": true, "SQ": true, "TR": true, "MA": true, "SL": true, "LB": true, "AR": true,
+	"LN": true}
+
+// Validates the Node and returns the game info Node in effect after it.
+func (v *validator) validateNode(node *Node, root bool, gameInfo *Node) *Node {
+	seen := map[string]bool{}
+	move, setup, invalidMarkup := false, false, false
+
+	for _, property := range node.Properties {
+		switch property.Ident {
+		case "B", "W":
+			move = true
+		}
+	}
+
+	for _, property := range node.Properties {
+		if property.Ident == "" {
+			v.add(node, property, "Property does not have an ident")
+			continue
+		}
+
+		if seen[property.Ident] {
+			v.add(node, property, "Property is given more than once in the Node")
+		}
+		seen[property.Ident] = true
+
+		info := property.Info()
+		if info.Value == ValueUnknown {
+			if len(property.Values) == 0 {
+				v.add(node, property, "Property does not have any values")
+			}
+
+			continue
+		}
+
+		switch info.Type {
+		case PropertyTypeRoot:
+			if !root {
+				v.add(node, property, "Root property outside of the root Node")
+			}
+		case PropertyTypeMove:
+			if !move {
+				v.add(node, property, "Move property in a Node without a move")
+			}
+		case PropertyTypeSetup:
+			setup = true
+		case PropertyTypeGameInfo:
+			if gameInfo != nil && gameInfo != node {
+				v.add(node, property, "Game info was already given above this Node")
+			}
+		}
+
+		issues := len(v.issues)
+		v.validateValues(node, property, info)
+
+		if len(v.issues) > issues && markupIdents[property.Ident] {
+			invalidMarkup = true
+		}
+	}
+
+	if move && setup {
+		v.add(node, nil, "Node contains both move and setup properties")
+	}
+
+	// Markup that can't be parsed has already been reported
+	if err := node.ValidateMarkup(); err != nil && !invalidMarkup {
+		v.add(node, nil, "%s", err)
+	}
+
+	for _, property := range node.Properties {
+		if property.Info().Type == PropertyTypeGameInfo && gameInfo == nil {
+			gameInfo = node
+		}
+	}
+
+	return gameInfo
+}
+
+func (v *validator) validateValues(node *Node, property *Property, info PropertyInfo) {
+	switch {
+	case len(property.Values) == 0:
+		v.add(node, property, "Property does not have any values")
+		return
+	case len(property.Values) > 1 && !info.List:
+		v.add(node, property, "Property does not take a list of values")
+	}
+
+	for _, value := range property.Values {
+		if value == "" && (info.EList || info.Value == ValueMove) && len(property.Values) == 1 {
+			continue
+		}
+
+		if info.Composed == ValueUnknown {
+			if info.List && (info.Value == ValuePoint || info.Value == ValueStone) {
+				v.validatePointList(node, property, value)
+				continue
+			}
+
+			v.validateValue(node, property, info.Value, value)
+			continue
+		}
+
+		first, second, composed := Compose(value).Split()
+		v.validateValue(node, property, info.Value, first)
+		if composed {
+			v.validateValue(node, property, info.Composed, second)
+		}
+	}
+}
+
+func (v *validator) validatePointList(node *Node, property *Property, value string) {
+	points, err := ParsePointList(value)
+	if err != nil {
+		v.add(node, property, "%s", err)
+		return
+	}
+
+	for _, point := range points {
+		if !point.OnBoard(v.width, v.height) {
+			v.add(node, property, "Point %s is not on the board", point)
+			return
+		}
+	}
+}
+
+// Validates a single value or a part of a composed value.
+func (v *validator) validateValue(node *Node, property *Property, valueType ValueType, value string) {
+	valid := true
+
+	switch valueType {
+	case ValueNone:
+		valid = value == ""
+	case ValueNumber:
+		_, err := strconv.Atoi(value)
+		valid = err == nil
+	case ValueReal:
+		_, err := strconv.ParseFloat(value, 64)
+		valid = err == nil
+	case ValueDouble:
+		valid = value == "1" || value == "2"
+	case ValueColor:
+		valid = value == "B" || value == "W"
+	case ValueMove:
+		if value == "" || (value == "tt" && v.width <= 19 && v.height <= 19) {
+			return
+		}
+
+		fallthrough
+	case ValuePoint, ValueStone:
+		point, err := ParsePoint(value)
+		if err != nil {
+			v.add(node, property, "%s", err)
+			return
+		}
+
+		if !point.OnBoard(v.width, v.height) {
+			v.add(node, property, "Point %s is not on the board", point)
+		}
+
+		return
+	}
+
+	if !valid {
+		v.add(node, property, "Invalid value %q", value)
+	}
+}
@@ -0,0 +1,76 @@
+package sgf
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var tests = []struct {
+		data   string
+		issues []string
+	}{
+		{"(;FF[4]GM[1]SZ[19];B[pd];W[dp](;B[tt])(;B[]))", []string{}},
+		{"(;SZ[19:13]AB[aa:cc]LB[ab:x]AR[aa:bb];B[sm]C[a\\]b]BL[12.5]OB[3])", []string{}},
+		{"(;FF[x]DM[3]PL[X];KO[])", []string{
+			"FF: Invalid value \"x\"",
+			"DM: Invalid value \"3\"",
+			"PL: Invalid value \"X\"",
+			"KO: Move property in a Node without a move",
+		}},
+		{"(;SZ[9];B[jj];W[aa][bb];AB[aa:jj])", []string{
+			"B: Point jj is not on the board",
+			"W: Property does not take a list of values",
+			"AB: Point ja is not on the board",
+		}},
+		{"(;SZ[9];B[aa]AW[bb];GM[1]C[x]C[y])", []string{
+			"Node contains both move and setup properties",
+			"GM: Root property outside of the root Node",
+			"C: Property is given more than once in the Node",
+		}},
+		{"(;PB[A];B[aa](;PW[B])(;W[bb]))", []string{
+			"PW: Game info was already given above this Node",
+		}},
+		{"(;B[aa](;PB[A];W[bb])(;PB[B];W[cc]))", []string{}},
+		{"(;CR[aa]TR[aa]XX[foo])", []string{
+			"Point aa has both CR and TR",
+		}},
+		{"(;CR[aa]SQ[aa]BM[1])", []string{
+			"BM: Move property in a Node without a move",
+			"Point aa has both CR and SQ",
+		}},
+		{"(;C[a]C[b]CR[aa]SQ[aa])", []string{
+			"C: Property is given more than once in the Node",
+			"Point aa has both CR and SQ",
+		}},
+		{"(;SZ[9]CR[jj]SQ[aa])", []string{
+			"CR: Point jj is not on the board",
+		}},
+		{"(;SZ[0])", []string{
+			"SZ: Invalid board size \"0\"",
+		}},
+	}
+
+	for _, test := range tests {
+		collection, err := ParseSgf(test.data)
+		if err != nil {
+			t.Errorf("ParseSgf(%s) returned error: %s", test.data, err)
+			continue
+		}
+
+		issues := collection.Validate()
+		if len(issues) != len(test.issues) {
+			t.Errorf("Validate(%s) mismatch. Wanted: %v, got: %v", test.data, test.issues, issues)
+			continue
+		}
+
+		for i, issue := range issues {
+			if issue.String() != test.issues[i] {
+				t.Errorf("Validate(%s) issue %d mismatch. Wanted: %s, got: %s", test.data, i, test.issues[i], issue)
+			}
+		}
+	}
+
+	if issues := (&Collection{}).Validate(); len(issues) != 1 || issues[0].Node != nil {
+		t.Errorf("Validate() of an empty collection mismatch. Got: %v", issues)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Package xmlsgf converts sgf Collections to XML and back.
+
+Collection, GameTree, Node and Property are mapped to elements of the same name. Variations are child gametree
+elements nested inside their parent gametree after its nodes:
+
+	<?xml version="1.0" encoding="UTF-8"?>
+	<collection>
+	  <gametree>
+	    <node>
+	      <property ident="FF"><value>4</value></property>
+	      <property ident="SZ"><value>19</value></property>
+	    </node>
+	    <node>
+	      <property ident="B"><value>pd</value></property>
+	    </node>
+	    <gametree>
+	      <node>
+	        <property ident="W"><value>dp</value></property>
+	      </node>
+	    </gametree>
+	  </gametree>
+	</collection>
+
+Values are stored as they are in Property.Values so the conversion does not lose any information. Characters that
+XML cannot represent, control characters other than tab, new line and carriage return and invalid UTF-8, are not
+replaced: XML returns an error for values containing them.
+*/
+package xmlsgf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"unicode/utf8"
+
+	"github.com/toikarin/sgf"
+)
+
+type xmlCollection struct {
+	XMLName   xml.Name      `xml:"collection"`
+	GameTrees []xmlGameTree `xml:"gametree"`
+}
+
+type xmlGameTree struct {
+	Nodes     []xmlNode     `xml:"node"`
+	GameTrees []xmlGameTree `xml:"gametree"`
+}
+
+type xmlNode struct {
+	Properties []xmlProperty `xml:"property"`
+}
+
+type xmlProperty struct {
+	Ident  string   `xml:"ident,attr"`
+	Values []string `xml:"value"`
+}
+
+var readFileFunc func(string) ([]byte, error) = ioutil.ReadFile
+
+// Parse given filename as a XML file.
+func ParseFile(filename string) (*sgf.Collection, error) {
+	bytes, err := readFileFunc(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes)
+}
+
+// Parse given XML data. Returns an error if the data does not contain a valid collection (see Collection.Valid).
+func Parse(data []byte) (*sgf.Collection, error) {
+	var c xmlCollection
+	if err := xml.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+
+	collection := &sgf.Collection{}
+	for _, gameTree := range c.GameTrees {
+		collection.AddGameTree(fromXMLGameTree(gameTree))
+	}
+
+	if !collection.Valid() {
+		return nil, errors.New("Invalid collection")
+	}
+
+	return collection, nil
+}
+
+// Converts the collection to XML. Nested elements are indented with the given indentation, if it's empty the XML
+// is written on a single line. Returns an error if a value contains characters that XML cannot represent.
+func XML(collection *sgf.Collection, indent string) ([]byte, error) {
+	c := xmlCollection{}
+	for _, gameTree := range collection.GameTrees {
+		gt, err := toXMLGameTree(gameTree)
+		if err != nil {
+			return nil, err
+		}
+
+		c.GameTrees = append(c.GameTrees, gt)
+	}
+
+	data, err := xml.MarshalIndent(c, "", indent)
+	if err != nil {
+		return nil, err
+	}
+
+	if indent != "" {
+		data = append(data, '\n')
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+//
+// To XML
+//
+
+func toXMLGameTree(gameTree *sgf.GameTree) (xmlGameTree, error) {
+	gt := xmlGameTree{}
+
+	for _, node := range gameTree.Nodes {
+		n := xmlNode{}
+		for _, property := range node.Properties {
+			for _, value := range property.Values {
+				if !validXMLText(value) {
+					return gt, errors.New(fmt.Sprintf("%s: Value %q contains characters that XML cannot represent",
+						property.Ident, value))
+				}
+			}
+
+			n.Properties = append(n.Properties, xmlProperty{Ident: property.Ident, Values: property.Values})
+		}
+
+		gt.Nodes = append(gt.Nodes, n)
+	}
+
+	for _, childGameTree := range gameTree.GameTrees {
+		child, err := toXMLGameTree(childGameTree)
+		if err != nil {
+			return gt, err
+		}
+
+		gt.GameTrees = append(gt.GameTrees, child)
+	}
+
+	return gt, nil
+}
+
+// Returns true if the text is valid UTF-8 and consists of characters allowed in XML 1.0 documents.
+func validXMLText(text string) bool {
+	if !utf8.ValidString(text) {
+		return false
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+		case r < 0x20, r >= 0xd800 && r < 0xe000, r == 0xfffe || r == 0xffff:
+			return false
+		}
+	}
+
+	return true
+}
+
+//
+// From XML
+//
+
+func fromXMLGameTree(gt xmlGameTree) *sgf.GameTree {
+	gameTree := &sgf.GameTree{Nodes: []*sgf.Node{}, GameTrees: []*sgf.GameTree{}}
+
+	for _, n := range gt.Nodes {
+		node := &sgf.Node{Properties: []*sgf.Property{}}
+		for _, p := range n.Properties {
+			node.AddProperty(&sgf.Property{Ident: p.Ident, Values: p.Values})
+		}
+
+		gameTree.AddNode(node)
+	}
+
+	for _, childGameTree := range gt.GameTrees {
+		gameTree.AddGameTree(fromXMLGameTree(childGameTree))
+	}
+
+	return gameTree
+}
@@ -0,0 +1,165 @@
+package xmlsgf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/toikarin/sgf"
+)
+
+func TestRoundTrip(t *testing.T) {
+	// Same as the TestParse fixtures of the sgf package
+	var tests = []string{
+		"(;)",
+		"(;;)",
+		"(;FF[4])",
+		"(;FF[4][5])",
+		"(;FF[4]SZ[1](;PB[Black]))",
+		"(;FF[4]C[Text with \\] and <xml> & \"quotes\"\nand a new line];B[pd](;W[dp];B[])(;W[dd]))",
+		"(;FF[4])(;FF[4]AB[aa:cc]LB[aa:a\\:b])",
+		"(;C[tab\tcarriage return\r\nand \u00e4])",
+	}
+
+	for _, test := range tests {
+		collection, err := sgf.ParseSgf(test)
+		if err != nil {
+			t.Fatalf("ParseSgf(%s) returned error: %s", test, err)
+		}
+
+		for _, indent := range []string{"", "  "} {
+			data, err := XML(collection, indent)
+			if err != nil {
+				t.Errorf("XML(%s) returned error: %s", test, err)
+				continue
+			}
+
+			parsed, err := Parse(data)
+			if err != nil {
+				t.Errorf("Parse(XML(%s)) returned error: %s", test, err)
+				continue
+			}
+
+			if got := parsed.Sgf(sgf.NoNewLinesSgfFormat); got != collection.Sgf(sgf.NoNewLinesSgfFormat) {
+				t.Errorf("Parse(XML(%s)) mismatch. Got: %s.", test, got)
+			}
+		}
+	}
+}
+
+func TestXMLErrors(t *testing.T) {
+	// Characters that XML cannot represent would be replaced and lost in the round-trip
+	var errTests = []string{
+		"(;C[a\x01b\x0cc])",
+		"(;B[pd](;W[dp])(;W[dd]C[\x1b]))",
+	}
+
+	for _, test := range errTests {
+		collection, err := sgf.ParseSgf(test)
+		if err != nil {
+			t.Fatalf("ParseSgf(%q) returned error: %s", test, err)
+		}
+
+		if _, err := XML(collection, ""); err == nil {
+			t.Errorf("XML(%q) did not return error.", test)
+		}
+	}
+}
+
+func TestXML(t *testing.T) {
+	collection, err := sgf.ParseSgf("(;FF[4](;B[pd])(;W[]))")
+	if err != nil {
+		t.Fatalf("ParseSgf returned error: %s", err)
+	}
+
+	var tests = []struct {
+		indent string
+		wanted string
+	}{
+		{"", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+			"<collection><gametree><node><property ident=\"FF\"><value>4</value></property></node>" +
+			"<gametree><node><property ident=\"B\"><value>pd</value></property></node></gametree>" +
+			"<gametree><node><property ident=\"W\"><value></value></property></node></gametree>" +
+			"</gametree></collection>"},
+		{"  ", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+			"<collection>\n" +
+			"  <gametree>\n" +
+			"    <node>\n" +
+			"      <property ident=\"FF\">\n" +
+			"        <value>4</value>\n" +
+			"      </property>\n" +
+			"    </node>\n" +
+			"    <gametree>\n" +
+			"      <node>\n" +
+			"        <property ident=\"B\">\n" +
+			"          <value>pd</value>\n" +
+			"        </property>\n" +
+			"      </node>\n" +
+			"    </gametree>\n" +
+			"    <gametree>\n" +
+			"      <node>\n" +
+			"        <property ident=\"W\">\n" +
+			"          <value></value>\n" +
+			"        </property>\n" +
+			"      </node>\n" +
+			"    </gametree>\n" +
+			"  </gametree>\n" +
+			"</collection>\n"},
+	}
+
+	for _, test := range tests {
+		data, err := XML(collection, test.indent)
+		if err != nil {
+			t.Errorf("XML(%q) returned error: %s", test.indent, err)
+			continue
+		}
+
+		if string(data) != test.wanted {
+			t.Errorf("XML(%q) mismatch. Got: %s.", test.indent, data)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	collection, err := ParseFile("testdata/variations.xml")
+	if err != nil {
+		t.Fatalf("ParseFile returned error: %s", err)
+	}
+
+	wanted := "(;FF[4]SZ[19]C[Comment with <markup> & [brackets\\]];B[pd](;W[dp])(;W[]AB[aa][bb]))"
+	if got := collection.Sgf(sgf.NoNewLinesSgfFormat); got != wanted {
+		t.Errorf("ParseFile mismatch. Got: %s.", got)
+	}
+}
+
+func TestReadFileError(t *testing.T) {
+	oldReadFileFunc := readFileFunc
+	defer func() {
+		readFileFunc = oldReadFileFunc
+	}()
+
+	readFileFunc = func(filename string) ([]byte, error) {
+		return nil, errors.New("")
+	}
+
+	if _, err := ParseFile("foo"); err == nil {
+		t.Errorf("ParseFile did not return error.")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var errTests = []string{
+		"",
+		"<collection>",
+		"<collection></collection>",
+		"<collection><gametree></gametree></collection>",
+		"<collection><gametree><node><property><value>a</value></property></node></gametree></collection>",
+		"<collection><gametree><node><property ident=\"B\"></property></node></gametree></collection>",
+		"<foo><gametree><node></node></gametree></foo>",
+	}
+
+	for _, test := range errTests {
+		if _, err := Parse([]byte(test)); err == nil {
+			t.Errorf("Parse(%s) did not return error.", test)
+		}
+	}
+}